@@ -0,0 +1,8 @@
+//go:build mstsql
+// +build mstsql
+
+package main
+
+import (
+	_ "github.com/denisenkom/go-mssqldb"
+)
@@ -0,0 +1,8 @@
+//go:build pgsql
+// +build pgsql
+
+package main
+
+import (
+	_ "github.com/lib/pq"
+)
@@ -0,0 +1,120 @@
+// Command sqlgen read structure of database tables and write Go source
+// with typed struct per table, which expose columns as *sqlexp.TokenField.
+//
+// Database drivers are included by build tags:
+//
+//     go build -tags "sqlite pgsql mysql mstsql" github.com/d2r2/sqlg/cmd/sqlgen
+//     sqlgen -dialect sqlite -dsn ./foo.db -package models -out models/tables.go
+//
+// To generate code from tables defined in Go, use sqlgen.Generator directly.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlgen"
+	"github.com/d2r2/sqlg/sqlintrospect"
+)
+
+var dialects = map[string]sqldef.Dialect{
+	"pgsql":  sqldef.DI_PGSQL,
+	"mysql":  sqldef.DI_MYSQL,
+	"sqlite": sqldef.DI_SQLITE,
+	"mstsql": sqldef.DI_MSTSQL,
+}
+
+var drivers = map[sqldef.Dialect]string{
+	sqldef.DI_PGSQL:  "postgres",
+	sqldef.DI_MYSQL:  "mysql",
+	sqldef.DI_SQLITE: "sqlite3",
+	sqldef.DI_MSTSQL: "mssql",
+}
+
+func filterTables(tables []*sqldb.TableDef, names string) ([]*sqldb.TableDef, error) {
+	if names == "" {
+		return tables, nil
+	}
+	var filtered []*sqldb.TableDef
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, table := range tables {
+			if table.Name == name {
+				filtered = append(filtered, table)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Table \"%s\" is not found", name)
+		}
+	}
+	return filtered, nil
+}
+
+func run() error {
+	dialectName := flag.String("dialect", "", "database dialect: pgsql, mysql, sqlite or mstsql")
+	driverName := flag.String("driver", "", "database/sql driver name (default depends on dialect)")
+	dsn := flag.String("dsn", "", "data source name to connect to database")
+	schema := flag.String("schema", "", "schema to read tables from (default one if empty)")
+	tables := flag.String("tables", "", "comma separated tables to generate (all if empty)")
+	pkg := flag.String("package", "models", "package name of generated source")
+	out := flag.String("out", "", "file to write generated source (stdout if empty)")
+	flag.Parse()
+
+	dialect, ok := dialects[*dialectName]
+	if !ok {
+		return fmt.Errorf("Unknown dialect \"%s\"", *dialectName)
+	}
+	if *dsn == "" {
+		return fmt.Errorf("Data source name is not specified")
+	}
+	if *driverName == "" {
+		*driverName = drivers[dialect]
+	}
+	db, err := sql.Open(*driverName, *dsn)
+	if err != nil {
+		return fmt.Errorf("%v (is driver included by build tag \"%s\"?)",
+			err, *dialectName)
+	}
+	defer db.Close()
+	reader := sqlintrospect.NewReader(dialect, db)
+	if *schema != "" {
+		reader.SchemaName = schema
+	}
+	defs, err := reader.ReadTables()
+	if err != nil {
+		return err
+	}
+	defs, err = filterTables(defs, *tables)
+	if err != nil {
+		return err
+	}
+	gen := sqlgen.NewGenerator(*pkg, defs...)
+	gen.Command = fmt.Sprintf("sqlgen -dialect %s -package %s",
+		*dialectName, *pkg)
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return gen.Generate(w)
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "sqlgen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -1,14 +1,17 @@
 package sqlg
 
 import (
+	"github.com/d2r2/sqlg/sqlalter"
 	"github.com/d2r2/sqlg/sqlcore"
 	"github.com/d2r2/sqlg/sqlcreate"
 	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
 	"github.com/d2r2/sqlg/sqldelete"
 	"github.com/d2r2/sqlg/sqldrop"
 	"github.com/d2r2/sqlg/sqlexp"
 	"github.com/d2r2/sqlg/sqlinsert"
 	"github.com/d2r2/sqlg/sqlselect"
+	"github.com/d2r2/sqlg/sqltx"
 	"github.com/d2r2/sqlg/sqlupdate"
 )
 
@@ -17,6 +20,41 @@ func Select(fields ...sqlexp.Expr) sqlselect.Select {
 	return s
 }
 
+func SelectDistinct(fields ...sqlexp.Expr) sqlselect.Select {
+	s := sqlselect.NewSelectDistinct(fields...)
+	return s
+}
+
+func With(name string, sel sqlcore.SqlReady) sqlselect.With {
+	w := sqlselect.NewWith(name, sel)
+	return w
+}
+
+func WithCte(cte sqlselect.Cte) sqlselect.With {
+	w := sqlselect.NewWithCte(cte)
+	return w
+}
+
+func Union(left, right sqlcore.SqlReady) sqlselect.Compound {
+	c := sqlselect.NewUnion(left, right)
+	return c
+}
+
+func UnionAll(left, right sqlcore.SqlReady) sqlselect.Compound {
+	c := sqlselect.NewUnionAll(left, right)
+	return c
+}
+
+func Intersect(left, right sqlcore.SqlReady) sqlselect.Compound {
+	c := sqlselect.NewIntersect(left, right)
+	return c
+}
+
+func Except(left, right sqlcore.SqlReady) sqlselect.Compound {
+	c := sqlselect.NewExcept(left, right)
+	return c
+}
+
 func Insert(table sqlcore.Query, fields ...*sqlexp.TokenField) sqlinsert.Insert {
 	ins := sqlinsert.NewInsert(table, fields...)
 	return ins
@@ -45,6 +83,76 @@ func CreateTable(table *sqldb.TableDef) sqlcreate.CreateTable {
 	return create
 }
 
+func CreateTables(tables ...*sqldb.TableDef) sqlcreate.CreateTables {
+	create := sqlcreate.NewCreateTables(tables...)
+	return create
+}
+
+func CreateIndex(table *sqldb.TableDef, index *sqldb.IndexDef) sqlcreate.CreateIndex {
+	create := sqlcreate.NewCreateIndex(table, index)
+	return create
+}
+
+func CreateView(view *sqldb.ViewDef) sqlcreate.CreateView {
+	create := sqlcreate.NewCreateView(view)
+	return create
+}
+
+func AddColumn(table *sqldb.TableDef, field *sqldb.FieldDef) sqlalter.AlterTable {
+	alter := sqlalter.NewAddColumn(table, field)
+	return alter
+}
+
+func DropColumn(table *sqldb.TableDef, name string) sqlalter.AlterTable {
+	alter := sqlalter.NewDropColumn(table, name)
+	return alter
+}
+
+func RenameColumn(table *sqldb.TableDef, name, newName string) sqlalter.AlterTable {
+	alter := sqlalter.NewRenameColumn(table, name, newName)
+	return alter
+}
+
+func AlterColumnType(table *sqldb.TableDef, name string,
+	data *sqldef.DataDef) sqlalter.AlterTable {
+	alter := sqlalter.NewAlterColumnType(table, name, data)
+	return alter
+}
+
+func AlterColumnNullability(table *sqldb.TableDef, name string,
+	nullable bool) sqlalter.AlterTable {
+	alter := sqlalter.NewAlterColumnNullability(table, name, nullable)
+	return alter
+}
+
+func AlterColumnDefault(table *sqldb.TableDef, name string,
+	value interface{}) sqlalter.AlterTable {
+	alter := sqlalter.NewAlterColumnDefault(table, name, value)
+	return alter
+}
+
+func DropColumnDefault(table *sqldb.TableDef, name string) sqlalter.AlterTable {
+	alter := sqlalter.NewDropColumnDefault(table, name)
+	return alter
+}
+
+func AddConstraint(table *sqldb.TableDef,
+	constraint sqldb.ConstraintDef) sqlalter.AlterTable {
+	alter := sqlalter.NewAddConstraint(table, constraint)
+	return alter
+}
+
+func DropConstraint(table *sqldb.TableDef, name string) sqlalter.AlterTable {
+	alter := sqlalter.NewDropConstraint(table, name)
+	return alter
+}
+
+func AlterPrimaryKey(table *sqldb.TableDef,
+	fields ...*sqldb.FieldDef) sqlalter.AlterTable {
+	alter := sqlalter.NewAlterPrimaryKey(table, fields...)
+	return alter
+}
+
 func DropDatabase(databaseName string) sqldrop.DropDatabase {
 	drop := sqldrop.NewDropDatabase(databaseName)
 	return drop
@@ -54,3 +162,28 @@ func DropTable(table *sqldb.TableDef) sqldrop.DropTable {
 	r := sqldrop.NewDropTable(table)
 	return r
 }
+
+func DropIndex(table *sqldb.TableDef, name string) sqldrop.DropIndex {
+	r := sqldrop.NewDropIndex(table, name)
+	return r
+}
+
+func DropView(view *sqldb.ViewDef) sqldrop.DropView {
+	r := sqldrop.NewDropView(view)
+	return r
+}
+
+func Savepoint(name string) sqltx.Savepoint {
+	r := sqltx.NewSavepoint(name)
+	return r
+}
+
+func ReleaseSavepoint(name string) sqltx.ReleaseSavepoint {
+	r := sqltx.NewReleaseSavepoint(name)
+	return r
+}
+
+func RollbackSavepoint(name string) sqltx.RollbackSavepoint {
+	r := sqltx.NewRollbackSavepoint(name)
+	return r
+}
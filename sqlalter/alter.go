@@ -0,0 +1,780 @@
+package sqlalter
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqlcreate"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+type AlterKind int
+
+const (
+	AK_ADD_COLUMN AlterKind = iota
+	AK_DROP_COLUMN
+	AK_RENAME_COLUMN
+	AK_ALTER_COLUMN_TYPE
+	AK_ALTER_COLUMN_NULLABILITY
+	AK_ALTER_COLUMN_DEFAULT
+	AK_ADD_CONSTRAINT
+	AK_DROP_CONSTRAINT
+	AK_ALTER_PRIMARY_KEY
+	AK_RECREATE_TABLE
+)
+
+func (this AlterKind) String() string {
+	strs := map[AlterKind]string{
+		AK_ADD_COLUMN:               "add column",
+		AK_DROP_COLUMN:              "drop column",
+		AK_RENAME_COLUMN:            "rename column",
+		AK_ALTER_COLUMN_TYPE:        "alter column type",
+		AK_ALTER_COLUMN_NULLABILITY: "alter column nullability",
+		AK_ALTER_COLUMN_DEFAULT:     "alter column default",
+		AK_ADD_CONSTRAINT:           "add constraint",
+		AK_DROP_CONSTRAINT:          "drop constraint",
+		AK_ALTER_PRIMARY_KEY:        "alter primary key",
+		AK_RECREATE_TABLE:           "recreate table",
+	}
+	return strs[this]
+}
+
+type alterTableMaker struct {
+	Format *sqlcore.Format
+	Batch  *sqlcore.StatementBatch
+}
+
+func (this *alterTableMaker) runMaker(direct bool,
+	part sqlcore.SqlPart, stack *sqlcore.CallStack) error {
+	if direct == false {
+		var err error
+		switch part.GetPartKind() {
+		case sqlcore.SPK_ALTER_TABLE:
+			sect := part.(*alterTable)
+			err = sect.buildAlterTableSql(this, stack)
+			if err != nil {
+				return err
+			}
+		default:
+			err = e("Unexpected section during generating "+
+				"\"alter table\" statement: %v", part)
+		}
+		return err
+	}
+	return nil
+}
+
+func (this *alterTableMaker) BuildSql(part sqlcore.SqlPart,
+	format *sqlcore.Format) error {
+	f := *format
+	this.Format = &f
+	// alter table statement can't be parametrized
+	this.Format.AddOptions(sqlcore.BO_INLINE)
+	this.Batch = sqlcore.NewStatementBatch()
+	this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
+	return sqlcore.IterateSqlParents(false, part, this.runMaker)
+}
+
+func (this *alterTableMaker) GetExprBuildContext(partKind sqlcore.SqlPartKind,
+	subPartKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack,
+	format *sqlcore.Format) *sqlexp.ExprBuildContext {
+	context := sqlexp.NewExprBuildContext(partKind, subPartKind, stack, format, nil)
+	return context
+}
+
+type AlterTable interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+	ReferencedBy(tables ...*sqldb.TableDef) AlterTable
+}
+
+// Single modification of existing table, where table
+// definition describe table state before modification.
+type alterTable struct {
+	Table *sqldb.TableDef
+	Kind  AlterKind
+	// name of altered column or constraint
+	Name string
+	// new name of renamed column
+	NewName string
+	// added column
+	Field *sqldb.FieldDef
+	// new column attributes
+	Data       *sqldef.DataDef
+	IsNullable bool
+	// nil stands for dropping column default
+	Default *sqldb.DefaultDef
+	// added constraint
+	Constraint sqldb.ConstraintDef
+	// new primary key fields
+	Fields []*sqldb.FieldDef
+	// new table definition of recreated table
+	NewTable *sqldb.TableDef
+	// tables, which might refer to altered one
+	Referencing []*sqldb.TableDef
+}
+
+func NewAddColumn(table *sqldb.TableDef, field *sqldb.FieldDef) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_ADD_COLUMN,
+		Name: field.Name, Field: field}
+	return r
+}
+
+func NewDropColumn(table *sqldb.TableDef, name string) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_DROP_COLUMN, Name: name}
+	return r
+}
+
+func NewRenameColumn(table *sqldb.TableDef, name, newName string) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_RENAME_COLUMN,
+		Name: name, NewName: newName}
+	return r
+}
+
+func NewAlterColumnType(table *sqldb.TableDef, name string,
+	data *sqldef.DataDef) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_ALTER_COLUMN_TYPE,
+		Name: name, Data: data}
+	return r
+}
+
+func NewAlterColumnNullability(table *sqldb.TableDef, name string,
+	nullable bool) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_ALTER_COLUMN_NULLABILITY,
+		Name: name, IsNullable: nullable}
+	return r
+}
+
+// Set column default, where value either expression
+// or constant. Nil value stands for null.
+func NewAlterColumnDefault(table *sqldb.TableDef, name string,
+	value interface{}) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_ALTER_COLUMN_DEFAULT,
+		Name: name, Default: sqldb.NewDefaultDef(value)}
+	return r
+}
+
+func NewDropColumnDefault(table *sqldb.TableDef, name string) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_ALTER_COLUMN_DEFAULT,
+		Name: name}
+	return r
+}
+
+func NewAddConstraint(table *sqldb.TableDef,
+	constraint sqldb.ConstraintDef) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_ADD_CONSTRAINT,
+		Name: constraint.GetOrAdviceName(table), Constraint: constraint}
+	return r
+}
+
+func NewDropConstraint(table *sqldb.TableDef, name string) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_DROP_CONSTRAINT, Name: name}
+	return r
+}
+
+// Replace primary key with new one built from fields.
+// Empty fields list stands for primary key removal.
+func NewAlterPrimaryKey(table *sqldb.TableDef,
+	fields ...*sqldb.FieldDef) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_ALTER_PRIMARY_KEY,
+		Fields: fields}
+	return r
+}
+
+// Replace table with new one built from table definition, keeping data
+// of columns with the same names. Used for SQLite, when set of modifications
+// can't be done in place, to rebuild table once.
+func NewRecreateTable(table *sqldb.TableDef, newTable *sqldb.TableDef) AlterTable {
+	r := &alterTable{Table: table, Kind: AK_RECREATE_TABLE,
+		Name: table.Name, NewTable: newTable}
+	return r
+}
+
+// Specify tables, which might refer to altered table by foreign keys,
+// so table rebuild is refused, when it would fire "on delete" actions
+// of referencing foreign keys and change data of referencing tables.
+func (this *alterTable) ReferencedBy(tables ...*sqldb.TableDef) AlterTable {
+	this.Referencing = append(this.Referencing, tables...)
+	return this
+}
+
+// Find constraint of the table by name.
+func (this *alterTable) findConstraint(table *sqldb.TableDef,
+	name string) sqldb.ConstraintDef {
+	for _, fk := range table.ForeignKeys.Items {
+		if fk.GetOrAdviceName(table) == name {
+			return fk
+		}
+	}
+	for _, unique := range table.Uniques.Items {
+		if unique.GetOrAdviceName(table) == name {
+			return unique
+		}
+	}
+	for _, check := range table.Checks.Items {
+		if check.GetOrAdviceName(table) == name {
+			return check
+		}
+	}
+	return nil
+}
+
+func (this *alterTable) validate() error {
+	field := this.Table.Fields.Find(this.Name)
+	switch this.Kind {
+	case AK_ADD_COLUMN:
+		if field != nil {
+			return e("Column \"%s\" already exists in table \"%s\"",
+				this.Name, this.Table.Name)
+		}
+	case AK_ADD_CONSTRAINT:
+		return this.Constraint.Validate(this.Table)
+	case AK_DROP_CONSTRAINT:
+	case AK_ALTER_PRIMARY_KEY:
+		for _, item := range this.Fields {
+			if this.Table.Fields.Find(item.Name) == nil {
+				return e("Can't find primary key column \"%s\" in table \"%s\"",
+					item.Name, this.Table.Name)
+			}
+		}
+	case AK_RECREATE_TABLE:
+		if this.NewTable.Name != this.Table.Name {
+			return e("Recreated table \"%s\" should have the same name, "+
+				"but \"%s\" specified", this.Table.Name, this.NewTable.Name)
+		}
+	default:
+		if field == nil {
+			return e("Can't find column \"%s\" in table \"%s\"",
+				this.Name, this.Table.Name)
+		}
+		if this.Kind == AK_RENAME_COLUMN &&
+			this.Table.Fields.Find(this.NewName) != nil {
+			return e("Column \"%s\" already exists in table \"%s\"",
+				this.NewName, this.Table.Name)
+		}
+	}
+	return nil
+}
+
+// Get column definition with modified attributes.
+func (this *alterTable) getAlteredField() *sqldb.FieldDef {
+	field := *this.Table.Fields.Find(this.Name)
+	switch this.Kind {
+	case AK_ALTER_COLUMN_TYPE:
+		field.Data = this.Data
+	case AK_ALTER_COLUMN_NULLABILITY:
+		field.IsNullable = this.IsNullable
+	case AK_ALTER_COLUMN_DEFAULT:
+		field.Default = this.Default
+	}
+	return &field
+}
+
+// Get table definition with modification applied.
+func (this *alterTable) getAlteredTable() (*sqldb.TableDef, error) {
+	table := this.Table.Clone()
+	switch this.Kind {
+	case AK_ADD_COLUMN:
+		table.Fields.Items = append(table.Fields.Items, this.Field)
+	case AK_DROP_COLUMN:
+		field := table.Fields.Find(this.Name)
+		// remove column only when it's not used by indexes and constraints
+		for _, index := range table.Indexes.Items {
+			for _, item := range index.Fields {
+				if item.Field == field {
+					return nil, e("Column \"%s\" is used by index \"%s\", "+
+						"which should be dropped first", this.Name,
+						index.GetOrAdviceName(table))
+				}
+			}
+		}
+		var constraints []sqldb.ConstraintDef
+		for _, fk := range table.ForeignKeys.Items {
+			constraints = append(constraints, fk)
+		}
+		for _, unique := range table.Uniques.Items {
+			constraints = append(constraints, unique)
+		}
+		for _, check := range table.Checks.Items {
+			constraints = append(constraints, check)
+		}
+		var fields []*sqldb.FieldDef
+		for _, item := range table.Fields.Items {
+			if item != field {
+				fields = append(fields, item)
+			}
+		}
+		table.Fields.Items = fields
+		for _, constraint := range constraints {
+			if constraint.Validate(table) != nil {
+				return nil, e("Column \"%s\" is used by constraint \"%s\", "+
+					"which should be dropped first", this.Name,
+					constraint.GetOrAdviceName(table))
+			}
+		}
+	case AK_RENAME_COLUMN:
+		table.Fields.Find(this.Name).Name = this.NewName
+	case AK_ALTER_COLUMN_TYPE, AK_ALTER_COLUMN_NULLABILITY,
+		AK_ALTER_COLUMN_DEFAULT:
+		field := this.getAlteredField()
+		*table.Fields.Find(this.Name) = *field
+	case AK_ADD_CONSTRAINT:
+		switch item := this.Constraint.(type) {
+		case *sqldb.ForeignKeyDef:
+			table.ForeignKeys.Items = append(table.ForeignKeys.Items, item)
+		case *sqldb.UniqueDef:
+			table.Uniques.Items = append(table.Uniques.Items, item)
+		case *sqldb.CheckDef:
+			table.Checks.Items = append(table.Checks.Items, item)
+		}
+	case AK_ALTER_PRIMARY_KEY:
+		for _, field := range table.Fields.Items {
+			field.IsPrimaryKey = false
+			for _, item := range this.Fields {
+				if item.Name == field.Name {
+					field.IsPrimaryKey = true
+				}
+			}
+		}
+	case AK_RECREATE_TABLE:
+		table = this.NewTable.Clone()
+	case AK_DROP_CONSTRAINT:
+		constraint := this.findConstraint(table, this.Name)
+		if constraint == nil {
+			return nil, e("Can't find constraint \"%s\" in table \"%s\"",
+				this.Name, this.Table.Name)
+		}
+		var fks []*sqldb.ForeignKeyDef
+		for _, fk := range table.ForeignKeys.Items {
+			if fk != constraint {
+				fks = append(fks, fk)
+			}
+		}
+		table.ForeignKeys.Items = fks
+		var uniques []*sqldb.UniqueDef
+		for _, unique := range table.Uniques.Items {
+			if unique != constraint {
+				uniques = append(uniques, unique)
+			}
+		}
+		table.Uniques.Items = uniques
+		var checks []*sqldb.CheckDef
+		for _, check := range table.Checks.Items {
+			if check != constraint {
+				checks = append(checks, check)
+			}
+		}
+		table.Checks.Items = checks
+	}
+	return table, nil
+}
+
+// SQLite support only adding and renaming of columns,
+// so rest of modifications require table to be rebuilt.
+func (this *alterTable) rebuildRequired(maker *alterTableMaker) bool {
+	if maker.Format.Dialect != sqldef.DI_SQLITE {
+		return false
+	}
+	switch this.Kind {
+	case AK_ADD_COLUMN:
+		// SQLite can't add primary key column in place,
+		// as well as "not null" column without default value
+		return this.Field.GetOrAdviceIsPrimaryKey() || !this.Field.IsNullable &&
+			(this.Field.Default == nil || this.Field.Default.Value == nil)
+	case AK_RENAME_COLUMN:
+		return false
+	}
+	return true
+}
+
+// Build sequence of statements, which replace table
+// with new one, containing modification:
+//
+//	create table new_table1 (...)
+//	insert into new_table1 (column1, ...) select column1, ... from table1
+//	drop table table1
+//	alter table new_table1 rename to table1
+//	create index ... on table1 (...)
+//
+// Foreign key enforcement expected to be disabled during sequence
+// execution, which can't be done inside of transaction.
+func (this *alterTable) buildRebuildSql(maker *alterTableMaker,
+	stack *sqlcore.CallStack) error {
+	table, err := this.getAlteredTable()
+	if err != nil {
+		return err
+	}
+	// SQLite autoincrement column is always declared as
+	// "integer primary key", so primary key can't include
+	// other columns or exclude autoincrement one
+	var autoinc *sqldb.FieldDef
+	keys := false
+	for _, field := range table.Fields.Items {
+		if field.Data.Type.In(sqldef.DT_AUTOINC_INT | sqldef.DT_AUTOINC_INT_BIG) {
+			autoinc = field
+		}
+		keys = keys || field.IsPrimaryKey
+	}
+	if autoinc != nil && keys {
+		for _, field := range table.Fields.Items {
+			if field.IsPrimaryKey != (field == autoinc) {
+				return e("%v dialect requires autoincrement column \"%s\" "+
+					"to be the only primary key column of table \"%s\", "+
+					"so change column type in the same table recreate",
+					maker.Format.Dialect, autoinc.Name, this.Table.Name)
+			}
+		}
+	}
+	// drop of table delete all its rows, which fire "on delete"
+	// actions of referencing foreign keys, when foreign keys
+	// enforcement is on, so refuse to lose referencing data
+	ref, fk := sqldb.FindCascadeReference(this.Table, this.Referencing)
+	if ref != nil {
+		return e("%v dialect rebuild of table \"%s\" fires \"on delete %v\" "+
+			"action of foreign key \"%s\" of table \"%s\", so disable foreign "+
+			"keys outside of transaction and run rebuild without referencing tables",
+			maker.Format.Dialect, this.Table.Name, fk.OnDelete,
+			fk.GetOrAdviceName(ref), ref.Name)
+	}
+	format := maker.Format
+	tempName := f("new_%s", this.Table.Name)
+	batch, err := sqlcreate.NewCreateTableAs(table, tempName).GetSql(format)
+	if err != nil {
+		return err
+	}
+	maker.Batch = batch
+	var columns string
+	for _, field := range table.Fields.Items {
+		if this.Table.Fields.Find(field.Name) == nil {
+			continue
+		}
+		if columns != "" {
+			columns += ", "
+		}
+		columns += format.FormatObjectName(field.Name)
+	}
+	name := format.FormatTableName(this.Table.Name)
+	temp := format.FormatTableName(tempName)
+	stat := sqlcore.NewStatement(sqlcore.SS_EXEC)
+	stat.WriteString("insert into %s (%s)", temp, columns)
+	stat.WriteString(format.SectionDivider)
+	stat.WriteString("select %s from %s", columns, name)
+	maker.Batch.Add(stat)
+	stat = sqlcore.NewStatement(sqlcore.SS_EXEC)
+	stat.WriteString("drop table %s", name)
+	maker.Batch.Add(stat)
+	stat = sqlcore.NewStatement(sqlcore.SS_EXEC)
+	stat.WriteString("alter table %s rename to %s", temp, name)
+	maker.Batch.Add(stat)
+	for _, index := range table.Indexes.Items {
+		batch, err := sqlcreate.NewCreateIndex(table, index).GetSql(format)
+		if err != nil {
+			return err
+		}
+		maker.Batch.Items = append(maker.Batch.Items, batch.Items...)
+	}
+	return nil
+}
+
+func (this *alterTable) getSqlValue(format *sqlcore.Format,
+	stack *sqlcore.CallStack, value sqlexp.Expr) (*sqlcore.Statement, error) {
+	context := sqlexp.NewExprBuildContext(sqlcore.SPK_ALTER_TABLE,
+		sqlcore.SSPK_EXPR1, stack, format, nil)
+	return value.GetSql(context)
+}
+
+// Microsoft T-SQL keep column default as constraint with generated
+// name, so it's found and dropped with dynamic statement.
+func (this *alterTable) getSqlDropDefaultMicrosoftCase(format *sqlcore.Format,
+	stack *sqlcore.CallStack) (*sqlcore.Statement, error) {
+	name := format.FormatTableName(this.Table.Name)
+	tableName, err := this.getSqlValue(format, stack,
+		&sqlexp.TokenValue{Value: name})
+	if err != nil {
+		return nil, err
+	}
+	columnName, err := this.getSqlValue(format, stack,
+		&sqlexp.TokenValue{Value: this.Name})
+	if err != nil {
+		return nil, err
+	}
+	command, err := this.getSqlValue(format, stack,
+		&sqlexp.TokenValue{Value: f("alter table %s drop constraint ", name)})
+	if err != nil {
+		return nil, err
+	}
+	stat := sqlcore.NewStatement(sqlcore.SS_EXEC)
+	stat.WriteString("declare @name sysname")
+	stat.WriteString(format.SectionDivider)
+	stat.WriteString("select @name = d.name from sys.default_constraints d ")
+	stat.WriteString("join sys.columns c on c.object_id = d.parent_object_id ")
+	stat.WriteString("and c.column_id = d.parent_column_id")
+	stat.WriteString(format.SectionDivider)
+	stat.AppendStatPartsFormat("where d.parent_object_id = object_id(%s) "+
+		"and c.name = %s", tableName, columnName)
+	stat.WriteString(format.SectionDivider)
+	stat.AppendStatPartsFormat("if @name is not null "+
+		"exec(%s + quotename(@name))", command)
+	return stat, nil
+}
+
+func (this *alterTable) buildAlterColumnSql(maker *alterTableMaker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	format := maker.Format
+	field := this.getAlteredField()
+	column := format.FormatObjectName(this.Name)
+	switch format.Dialect {
+	case sqldef.DI_PGSQL:
+		stat.WriteString("alter column %s ", column)
+		if this.Kind == AK_ALTER_COLUMN_TYPE {
+			stat.WriteString("type ")
+			return sqlcreate.BuildSqlFieldDataType(stat, format, stack, field)
+		} else if field.IsNullable {
+			stat.WriteString("drop not null")
+		} else {
+			stat.WriteString("set not null")
+		}
+	case sqldef.DI_MYSQL:
+		// MySQL require full column definition
+		stat.WriteString("modify column ")
+		return sqlcreate.BuildSqlField(stat, format, stack,
+			this.Table, field, false)
+	case sqldef.DI_MSTSQL:
+		stat.WriteString("alter column %s ", column)
+		err := sqlcreate.BuildSqlFieldDataType(stat, format, stack, field)
+		if err != nil {
+			return err
+		}
+		if field.IsNullable {
+			stat.WriteString(" null")
+		} else {
+			stat.WriteString(" not null")
+		}
+	default:
+		return e("Can't produce \"%v\" section in notation \"%v\"",
+			this.Kind, format.Dialect)
+	}
+	return nil
+}
+
+func (this *alterTable) buildAlterColumnDefaultSql(maker *alterTableMaker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	format := maker.Format
+	column := format.FormatObjectName(this.Name)
+	var value *sqlcore.Statement
+	if this.Default != nil {
+		var err error
+		value = sqlcore.NewStatement(sqlcore.SS_UNDEF)
+		value.WriteString("null")
+		if this.Default.Value != nil {
+			value, err = this.getSqlValue(format, stack, this.Default.Value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	switch format.Dialect {
+	case sqldef.DI_PGSQL, sqldef.DI_MYSQL:
+		stat.WriteString("alter column %s ", column)
+		if value != nil {
+			stat.AppendStatPartsFormat("set default %s", value)
+		} else {
+			stat.WriteString("drop default")
+		}
+	case sqldef.DI_MSTSQL:
+		stat2, err := this.getSqlDropDefaultMicrosoftCase(format, stack)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			maker.Batch.Replace(stat, stat2)
+			return nil
+		}
+		maker.Batch.Items = append([]*sqlcore.Statement{stat2},
+			maker.Batch.Items...)
+		name := format.FormatObjectName(f("DF_%s_%s", this.Table.Name, this.Name))
+		stat.WriteString("add constraint %s ", name)
+		stat.AppendStatPartsFormat("default %s", value)
+		stat.WriteString(" for %s", column)
+	default:
+		return e("Can't produce \"%v\" section in notation \"%v\"",
+			this.Kind, format.Dialect)
+	}
+	return nil
+}
+
+func (this *alterTable) buildDropConstraintSql(maker *alterTableMaker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	format := maker.Format
+	name := format.FormatObjectName(this.Name)
+	if format.Dialect == sqldef.DI_MYSQL {
+		// MySQL distinguish constraint kinds on drop
+		switch this.findConstraint(this.Table, this.Name).(type) {
+		case *sqldb.ForeignKeyDef:
+			stat.WriteString("drop foreign key %s", name)
+		case *sqldb.UniqueDef:
+			stat.WriteString("drop index %s", name)
+		case *sqldb.CheckDef:
+			stat.WriteString("drop check %s", name)
+		default:
+			stat.WriteString("drop constraint %s", name)
+		}
+	} else {
+		stat.WriteString("drop constraint %s", name)
+	}
+	return nil
+}
+
+func (this *alterTable) buildAlterPrimaryKeySql(maker *alterTableMaker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	format := maker.Format
+	table, err := this.getAlteredTable()
+	if err != nil {
+		return err
+	}
+	oldPk := this.Table.GetOrAdvicePrimaryKey()
+	newPk := table.GetOrAdvicePrimaryKey()
+	var columns string
+	for i, field := range newPk.Items {
+		if i > 0 {
+			columns += ", "
+		}
+		columns += format.FormatObjectName(field.Name)
+	}
+	header := stat.Sql()
+	switch format.Dialect {
+	case sqldef.DI_PGSQL, sqldef.DI_MYSQL:
+		if len(oldPk.Items) > 0 {
+			if format.Dialect == sqldef.DI_MYSQL {
+				stat.WriteString("drop primary key")
+			} else {
+				stat.WriteString("drop constraint %s",
+					format.FormatObjectName(oldPk.Name))
+			}
+			if len(newPk.Items) > 0 {
+				stat.WriteString(", ")
+			}
+		}
+		if len(newPk.Items) > 0 {
+			if format.Dialect == sqldef.DI_MYSQL {
+				stat.WriteString("add primary key (%s)", columns)
+			} else {
+				stat.WriteString("add constraint %s primary key (%s)",
+					format.FormatObjectName(newPk.Name), columns)
+			}
+		}
+	case sqldef.DI_MSTSQL:
+		// Microsoft T-SQL doesn't let drop and add constraint
+		// within single statement, so these are split
+		if len(oldPk.Items) > 0 {
+			stat.WriteString("drop constraint %s",
+				format.FormatObjectName(oldPk.Name))
+			if len(newPk.Items) > 0 {
+				stat = sqlcore.NewStatement(sqlcore.SS_EXEC)
+				stat.WriteString(header)
+				maker.Batch.Add(stat)
+			}
+		}
+		if len(newPk.Items) > 0 {
+			stat.WriteString("add constraint %s primary key (%s)",
+				format.FormatObjectName(newPk.Name), columns)
+		}
+	default:
+		return e("Can't produce \"%v\" section in notation \"%v\"",
+			this.Kind, format.Dialect)
+	}
+	return nil
+}
+
+func (this *alterTable) buildAlterTableSql(maker *alterTableMaker,
+	stack *sqlcore.CallStack) error {
+	err := this.validate()
+	if err != nil {
+		return err
+	}
+	if this.Kind == AK_RECREATE_TABLE &&
+		maker.Format.Dialect != sqldef.DI_SQLITE {
+		return e("Can't recreate table \"%s\" in notation \"%v\", "+
+			"since names of constraints might be already in use",
+			this.Table.Name, maker.Format.Dialect)
+	}
+	if this.rebuildRequired(maker) {
+		return this.buildRebuildSql(maker, stack)
+	}
+	format := maker.Format
+	stat := maker.Batch.Last()
+	stat.WriteString("alter table %s ", format.FormatTableName(this.Table.Name))
+	switch this.Kind {
+	case AK_ADD_COLUMN:
+		if format.Dialect == sqldef.DI_MSTSQL {
+			stat.WriteString("add ")
+		} else {
+			stat.WriteString("add column ")
+		}
+		err = sqlcreate.BuildSqlField(stat, format, stack,
+			this.Table, this.Field, true)
+	case AK_DROP_COLUMN:
+		if format.Dialect == sqldef.DI_MSTSQL &&
+			this.Table.Fields.Find(this.Name).Default != nil {
+			// column default should be dropped first
+			stat2, err := this.getSqlDropDefaultMicrosoftCase(format, stack)
+			if err != nil {
+				return err
+			}
+			maker.Batch.Items = append([]*sqlcore.Statement{stat2},
+				maker.Batch.Items...)
+		}
+		stat.WriteString("drop column %s", format.FormatObjectName(this.Name))
+	case AK_RENAME_COLUMN:
+		if format.Dialect == sqldef.DI_MSTSQL {
+			stat = sqlcore.NewStatement(sqlcore.SS_EXEC)
+			value, err := this.getSqlValue(format, stack, &sqlexp.TokenValue{
+				Value: f("%s.%s", this.Table.Name, this.Name)})
+			if err != nil {
+				return err
+			}
+			newValue, err := this.getSqlValue(format, stack,
+				&sqlexp.TokenValue{Value: this.NewName})
+			if err != nil {
+				return err
+			}
+			stat.AppendStatPartsFormat("exec sp_rename %s, %s, 'COLUMN'",
+				value, newValue)
+			maker.Batch.Replace(maker.Batch.Last(), stat)
+		} else {
+			stat.WriteString("rename column %s to %s",
+				format.FormatObjectName(this.Name),
+				format.FormatObjectName(this.NewName))
+		}
+	case AK_ALTER_COLUMN_TYPE, AK_ALTER_COLUMN_NULLABILITY:
+		err = this.buildAlterColumnSql(maker, stat, stack)
+	case AK_ALTER_COLUMN_DEFAULT:
+		err = this.buildAlterColumnDefaultSql(maker, stat, stack)
+	case AK_ADD_CONSTRAINT:
+		stat.WriteString("add ")
+		err = sqlcreate.BuildSqlConstraint(stat, format, stack,
+			this.Table, this.Constraint)
+	case AK_DROP_CONSTRAINT:
+		err = this.buildDropConstraintSql(maker, stat, stack)
+	case AK_ALTER_PRIMARY_KEY:
+		err = this.buildAlterPrimaryKeySql(maker, stat, stack)
+	}
+	return err
+}
+
+func (this *alterTable) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &alterTableMaker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, nil
+}
+
+func (this *alterTable) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_ALTER_TABLE
+}
+
+func (this *alterTable) GetParent() sqlcore.SqlPart {
+	return nil
+}
@@ -0,0 +1,93 @@
+package sqlalter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+)
+
+// Create table "dept" and table "emp", which refer to "dept"
+// by foreign key with specified "on delete" action.
+func newDeptAndEmpTables(action sqldb.ForeignKeyAction) (*sqldb.TableDef,
+	*sqldb.TableDef) {
+	dept := sqldb.Table("dept")
+	dept.Fields.AddAutoinc("id")
+	dept.Fields.AddUnicodeVariable("name", 50)
+	dept.Fields.AddUnicodeVariable("note", 100)
+	dept.Indexes.AddIndex("", dept.Fields.Find("name"))
+	emp := sqldb.Table("emp")
+	emp.Fields.AddAutoinc("id")
+	field := emp.Fields.AddInt("dept_id")
+	emp.ForeignKeys.AddForeignKey("", []*sqldb.FieldDef{field},
+		dept).OnDeleteDo(action)
+	return dept, emp
+}
+
+func TestRebuildTableSqlite(t *testing.T) {
+	dept, _ := newDeptAndEmpTables(sqldb.FKA_NO_ACTION)
+	data := sqldef.NewDataDef(sqldef.DT_UNICODE_VARCHAR, 100, 0)
+	batch, err := NewAlterColumnType(dept, "name", data).
+		GetSql(sqlcore.NewFormat(sqldef.DI_SQLITE))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"create table new_dept (",
+		"insert into new_dept (id, name, note)\nselect id, name, note from dept",
+		"drop table dept",
+		"alter table new_dept rename to dept",
+		"create index IX_dept_name",
+	}
+	if len(batch.Items) != len(want) {
+		t.Fatalf("expected %d statements, got %d", len(want), len(batch.Items))
+	}
+	for i, stat := range batch.Items {
+		if !strings.HasPrefix(stat.Sql(), want[i]) {
+			t.Errorf("statement %d: expected prefix %q in:\n%s",
+				i+1, want[i], stat.Sql())
+		}
+	}
+}
+
+func TestRebuildTableSqliteReferenced(t *testing.T) {
+	cases := []struct {
+		action sqldb.ForeignKeyAction
+		err    bool
+	}{
+		{sqldb.FKA_NO_ACTION, false},
+		{sqldb.FKA_RESTRICT, false},
+		{sqldb.FKA_CASCADE, true},
+		{sqldb.FKA_SET_NULL, true},
+		{sqldb.FKA_SET_DEFAULT, true},
+	}
+	for _, c := range cases {
+		dept, emp := newDeptAndEmpTables(c.action)
+		_, err := NewDropColumn(dept, "note").ReferencedBy(dept, emp).
+			GetSql(sqlcore.NewFormat(sqldef.DI_SQLITE))
+		if c.err && err == nil {
+			t.Errorf("%v: expected error", c.action)
+		} else if !c.err && err != nil {
+			t.Errorf("%v: unexpected error: %v", c.action, err)
+		}
+		// other dialects drop column in place
+		_, err = NewDropColumn(dept, "note").ReferencedBy(dept, emp).
+			GetSql(sqlcore.NewFormat(sqldef.DI_PGSQL))
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", c.action, err)
+		}
+	}
+}
+
+func TestRebuildTableSqliteAutoincKey(t *testing.T) {
+	emp := sqldb.Table("emp")
+	emp.Fields.AddAutoinc("id")
+	code := emp.Fields.AddInt("code")
+	_, err := NewAlterPrimaryKey(emp, code).
+		GetSql(sqlcore.NewFormat(sqldef.DI_SQLITE))
+	if err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -31,6 +31,68 @@ type QueryAlias interface {
 	GetAlias() string
 }
 
+// Query referenced by name only, without any table
+// or subquery behind, like common table expression.
+type QueryNamed interface {
+	Query
+	GetName() string
+}
+
+// Columns of named query (view, common table expression), which
+// are taken either from explicit column list, or from query named
+// one is defined by, when list is empty. Query obtained on demand.
+type ColumnList struct {
+	Columns  []string
+	GetQuery func() (Query, error)
+}
+
+func (this ColumnList) GetColumnCount() (int, error) {
+	if len(this.Columns) > 0 {
+		return len(this.Columns), nil
+	}
+	query, err := this.GetQuery()
+	if err != nil {
+		return 0, err
+	}
+	return query.GetColumnCount()
+}
+
+func (this ColumnList) ColumnIsAmbiguous(name string) (bool, error) {
+	if len(this.Columns) > 0 {
+		found := false
+		for _, column := range this.Columns {
+			if column == name {
+				if found {
+					return true, nil
+				}
+				found = true
+			}
+		}
+		return false, nil
+	}
+	query, err := this.GetQuery()
+	if err != nil {
+		return false, err
+	}
+	return query.ColumnIsAmbiguous(name)
+}
+
+func (this ColumnList) ColumnExists(name string) (bool, error) {
+	if len(this.Columns) > 0 {
+		for _, column := range this.Columns {
+			if column == name {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	query, err := this.GetQuery()
+	if err != nil {
+		return false, err
+	}
+	return query.ColumnExists(name)
+}
+
 type SqlReady interface {
 	GetSql(format *Format) (sql *StatementBatch, err error)
 }
@@ -41,6 +103,17 @@ const (
 	JK_INNER JoinKind = iota
 	JK_LEFT
 	JK_RIGHT
+	JK_FULL
+	JK_CROSS
+)
+
+type CompoundKind int
+
+const (
+	CK_UNION CompoundKind = iota
+	CK_UNION_ALL
+	CK_INTERSECT
+	CK_EXCEPT
 )
 
 type ConnInit interface {
@@ -38,11 +38,17 @@ type Format struct {
 	indentLevel    int
 	SectionDivider string
 	paramIndex     int
+	// Maximum number of parameters in single statement,
+	// initialized from dialect; 0 means no limit.
+	MaxParamCount int
+	// Data sources of outer statements, which let subquery
+	// refer to them; innermost scope goes first.
+	OuterDataSources [][]Query
 }
 
 func NewFormat(dialect sqldef.Dialect) *Format {
 	format := &Format{Dialect: dialect,
-		SectionDivider: "\n"}
+		SectionDivider: "\n", MaxParamCount: dialect.MaxParamCount()}
 	format.AddOptions(BO_COLUMN_NAME_AND_COUNT_VALIDATION)
 	if dialect.SupportMultipleStatementsInBatch() {
 		format.AddOptions(BO_SUPPORT_MULT_STATS_IN_A_BATCH)
@@ -133,6 +139,21 @@ func (this *Format) GetParamIndex() int {
 	return this.paramIndex
 }
 
+func (this *Format) SetParamIndex(index int) {
+	this.paramIndex = index
+}
+
+func (this *Format) PushOuterDataSources(queries []Query) {
+	scopes := [][]Query{queries}
+	this.OuterDataSources = append(scopes, this.OuterDataSources...)
+}
+
+func (this *Format) PopOuterDataSources() {
+	if len(this.OuterDataSources) > 0 {
+		this.OuterDataSources = this.OuterDataSources[1:]
+	}
+}
+
 func (this *Format) FormatObjectName(name string) string {
 	switch this.Dialect {
 	case sqldef.DI_MSTSQL:
@@ -174,13 +195,14 @@ func (this *Format) FormatDataSourceRef(query Query) (
 	stat := NewStatement(SS_UNDEF)
 	queryAlias, aliasBased := query.(QueryAlias)
 	tableBased, table := query.IsTableBased()
-	if tableBased == false && aliasBased == false {
-		return nil, e("Can't create sql statement for the object, " +
-			"since it's not a table neither has alias specified")
-	}
 	if aliasBased {
 		query = queryAlias.GetSource()
 	}
+	queryNamed, namedBased := query.(QueryNamed)
+	if tableBased == false && aliasBased == false && namedBased == false {
+		return nil, e("Can't create sql statement for the object, " +
+			"since it's not a table neither has alias specified")
+	}
 	sqlValid, sqlValidOk := query.(SqlReady)
 	if sqlValidOk {
 		this.IncIndentLevel()
@@ -196,6 +218,8 @@ func (this *Format) FormatDataSourceRef(query Query) (
 		stat = batch.Items[0]
 	} else if tableBased {
 		stat.WriteString(this.FormatTableName(table.GetName()))
+	} else if namedBased {
+		stat.WriteString(this.FormatObjectName(queryNamed.GetName()))
 	}
 	if aliasBased {
 		newst := NewStatement(SS_UNDEF)
@@ -9,38 +9,58 @@ const (
 	SPK_SELECT_FROM_OR_JOIN
 	SPK_SELECT_WHERE
 	SPK_SELECT_GROUP_BY
+	SPK_SELECT_HAVING
 	SPK_SELECT_ORDER_BY
+	SPK_SELECT_LIMIT
+	SPK_SELECT_COMPOUND
 	// insert statement sections
 	SPK_INSERT
 	SPK_INSERT_VALUES
 	SPK_INSERT_RETURNING
 	SPK_INSERT_FROM
+	SPK_INSERT_ON_CONFLICT
 	// update statement sections
 	SPK_UPDATE
 	SPK_UPDATE_FROM_OR_JOIN
 	SPK_UPDATE_WHERE
+	SPK_UPDATE_RETURNING
 	// delete statement sections
 	SPK_DELETE
 	SPK_DELETE_WHERE
+	SPK_DELETE_RETURNING
 	// create table sections
 	SPK_CREATE_TABLE
+	SPK_CREATE_INDEX
+	SPK_CREATE_VIEW
+	// alter table sections
+	SPK_ALTER_TABLE
 	// create database sections
 	SPK_CREATE_DATABASE
 	// drop table sections
 	SPK_DROP_TABLE
+	SPK_DROP_INDEX
+	SPK_DROP_VIEW
 	// drop database sections
 	SPK_DROP_DATABASE
+	// transaction sections
+	SPK_SAVEPOINT
+	SPK_RELEASE_SAVEPOINT
+	SPK_ROLLBACK_SAVEPOINT
 	// any
 	SPK_ANY = SPK_SELECT | SPK_SELECT_FROM_OR_JOIN |
-		SPK_SELECT_GROUP_BY | SPK_SELECT_ORDER_BY |
-		SPK_SELECT_WHERE |
+		SPK_SELECT_GROUP_BY | SPK_SELECT_HAVING | SPK_SELECT_ORDER_BY |
+		SPK_SELECT_WHERE | SPK_SELECT_LIMIT | SPK_SELECT_COMPOUND |
 		SPK_INSERT | SPK_INSERT_FROM |
 		SPK_INSERT_RETURNING | SPK_INSERT_VALUES |
+		SPK_INSERT_ON_CONFLICT |
 		SPK_UPDATE | SPK_UPDATE_FROM_OR_JOIN |
-		SPK_UPDATE_WHERE |
-		SPK_DELETE | SPK_DELETE_WHERE |
-		SPK_CREATE_DATABASE | SPK_CREATE_TABLE |
-		SPK_DROP_DATABASE | SPK_DROP_TABLE
+		SPK_UPDATE_WHERE | SPK_UPDATE_RETURNING |
+		SPK_DELETE | SPK_DELETE_WHERE | SPK_DELETE_RETURNING |
+		SPK_CREATE_DATABASE | SPK_CREATE_TABLE | SPK_CREATE_INDEX |
+		SPK_CREATE_VIEW | SPK_ALTER_TABLE |
+		SPK_DROP_DATABASE | SPK_DROP_TABLE | SPK_DROP_INDEX |
+		SPK_DROP_VIEW |
+		SPK_SAVEPOINT | SPK_RELEASE_SAVEPOINT | SPK_ROLLBACK_SAVEPOINT
 )
 
 func (this SqlPartKind) String() string {
@@ -50,20 +70,34 @@ func (this SqlPartKind) String() string {
 		SPK_SELECT_FROM_OR_JOIN: "select FROM JOIN on <...>",
 		SPK_SELECT_WHERE:        "select from WHERE <...>",
 		SPK_SELECT_GROUP_BY:     "select from GROUP BY <...>",
+		SPK_SELECT_HAVING:       "select from group by HAVING <...>",
 		SPK_SELECT_ORDER_BY:     "select from ORDER BY <....",
+		SPK_SELECT_LIMIT:        "select from LIMIT <...> OFFSET <...>",
+		SPK_SELECT_COMPOUND:     "select UNION|INTERSECT|EXCEPT select",
 		SPK_INSERT:              "INSERT INTO <...>",
 		SPK_INSERT_VALUES:       "insert into VALUES <....",
 		SPK_INSERT_RETURNING:    "insert into values RETURNING <...>",
 		SPK_INSERT_FROM:         "insert into FROM <....",
+		SPK_INSERT_ON_CONFLICT:  "insert into values ON CONFLICT <...>",
 		SPK_UPDATE:              "UPDATE <...>",
 		SPK_UPDATE_FROM_OR_JOIN: "update FROM JOIN on <...>",
 		SPK_UPDATE_WHERE:        "update WHERE [...]",
 		SPK_DELETE:              "DELETE [...]",
+		SPK_UPDATE_RETURNING:    "update where RETURNING [...]",
 		SPK_DELETE_WHERE:        "delete WHERE [...]",
+		SPK_DELETE_RETURNING:    "delete where RETURNING [...]",
 		SPK_CREATE_DATABASE:     "CREATE DATABASE [...]",
 		SPK_CREATE_TABLE:        "CREATE TABLE [...]",
+		SPK_CREATE_INDEX:        "CREATE INDEX [...]",
+		SPK_CREATE_VIEW:         "CREATE VIEW [...]",
+		SPK_ALTER_TABLE:         "ALTER TABLE [...]",
 		SPK_DROP_DATABASE:       "DROP DATABASE [...]",
 		SPK_DROP_TABLE:          "DROP TABLE [...]",
+		SPK_DROP_INDEX:          "DROP INDEX [...]",
+		SPK_DROP_VIEW:           "DROP VIEW [...]",
+		SPK_SAVEPOINT:           "SAVEPOINT [...]",
+		SPK_RELEASE_SAVEPOINT:   "RELEASE SAVEPOINT [...]",
+		SPK_ROLLBACK_SAVEPOINT:  "ROLLBACK TO SAVEPOINT [...]",
 	}
 	return strs[this]
 }
@@ -2,6 +2,7 @@ package sqlcore
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 )
 
@@ -90,6 +91,23 @@ func NewStatement(statType StatementType) *Statement {
 	return stat
 }
 
+// Querier is implemented by *sql.DB, *sql.Tx and *sql.Conn,
+// so batch can run either on connection pool, dedicated
+// connection or inside transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string,
+		args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string,
+		args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string,
+		args ...interface{}) *sql.Row
+}
+
+// TxBeginner is implemented by *sql.DB and *sql.Conn.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
 type StatementBatch struct {
 	Items []*Statement
 }
@@ -160,59 +178,126 @@ func (this *StatementBatch) Join(format *Format) error {
 	return nil
 }
 
-func (this *StatementBatch) Exec(db *sql.DB) (sql.Result, error) {
+// StatementError keep failure of statement from the batch:
+// statement index in Items, which was running or was
+// about to run, when the batch was interrupted.
+type StatementError struct {
+	Index     int
+	Statement *Statement
+	Err       error
+}
+
+func (this *StatementError) Error() string {
+	return f("Statement #%d failed: %v; %v", this.Index+1, this.Err, this.Statement)
+}
+
+func (this *StatementError) Unwrap() error {
+	return this.Err
+}
+
+func newStatementError(index int, stat *Statement, err error) error {
+	log.Error(err)
+	log.Error(stat)
+	return &StatementError{Index: index, Statement: stat, Err: err}
+}
+
+func (this *StatementBatch) Exec(db Querier) (sql.Result, error) {
+	return this.ExecContext(context.Background(), db)
+}
+
+// ExecContext run statements one by one, checking
+// for context cancellation before each statement.
+func (this *StatementBatch) ExecContext(ctx context.Context,
+	db Querier) (sql.Result, error) {
 	log.Debug(this)
 	var res sql.Result
-	for _, stat := range this.Items {
+	for i, stat := range this.Items {
 		if stat.Type != SS_EXEC {
 			return nil, e("Statement is not \"exec\" type: %v", stat)
 		}
-		res2, err := db.Exec(stat.Sql(), stat.Args...)
+		if err := ctx.Err(); err != nil {
+			return nil, newStatementError(i, stat, err)
+		}
+		res2, err := db.ExecContext(ctx, stat.Sql(), stat.Args...)
 		if err != nil {
-			log.Error(err)
-			log.Error(stat)
-			return nil, err
+			return nil, newStatementError(i, stat, err)
 		}
 		res = res2
 	}
 	return res, nil
 }
 
-func (this *StatementBatch) ExecQueryRow(db *sql.DB) (*sql.Row, error) {
+func (this *StatementBatch) ExecQueryRow(db Querier) (*sql.Row, error) {
+	return this.QueryRowContext(context.Background(), db)
+}
+
+// QueryRowContext run all statements except last one as "exec",
+// checking for context cancellation before each statement,
+// then query single row with last statement.
+func (this *StatementBatch) QueryRowContext(ctx context.Context,
+	db Querier) (*sql.Row, error) {
 	log.Debug(this)
 	for i, stat := range this.Items {
+		if err := ctx.Err(); err != nil {
+			return nil, newStatementError(i, stat, err)
+		}
 		if i < len(this.Items)-1 {
 			if stat.Type != SS_EXEC {
 				return nil, e("Statement is not \"exec\" type: %v", stat)
 			}
-			_, err := db.Exec(stat.Sql(), stat.Args...)
+			_, err := db.ExecContext(ctx, stat.Sql(), stat.Args...)
 			if err != nil {
-				log.Error(err)
-				log.Error(stat)
-				return nil, err
+				return nil, newStatementError(i, stat, err)
 			}
 		} else {
 			if stat.Type != SS_QUERY {
 				return nil, e("Statement is not \"query\" type: %v", stat)
 			}
-			row := db.QueryRow(stat.Sql(), stat.Args...)
+			row := db.QueryRowContext(ctx, stat.Sql(), stat.Args...)
 			return row, nil
 		}
 	}
 	return nil, nil
 }
 
-func (this *StatementBatch) Query(db *sql.DB) (*sql.Rows, error) {
+func (this *StatementBatch) Query(db Querier) (*sql.Rows, error) {
+	return this.QueryContext(context.Background(), db)
+}
+
+func (this *StatementBatch) QueryContext(ctx context.Context,
+	db Querier) (*sql.Rows, error) {
 	log.Debug(this)
 	if len(this.Items) > 1 {
 		return nil, e("Can't query multiple statments: %v", this)
 	}
 	stat := this.Items[0]
-	rows, err := db.Query(stat.Sql(), stat.Args...)
+	rows, err := db.QueryContext(ctx, stat.Sql(), stat.Args...)
 	if err != nil {
-		log.Error(err)
-		log.Error(stat)
-		return nil, err
+		return nil, newStatementError(0, stat, err)
 	}
 	return rows, nil
 }
+
+// ExecTx run all statements in a single transaction, which
+// is committed on success and rolled back on first failure.
+// Keep in mind, that MySQL implicitly commit transaction
+// on most of DDL statements, so they can't be rolled back.
+func (this *StatementBatch) ExecTx(ctx context.Context, db TxBeginner,
+	opts *sql.TxOptions) (sql.Result, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := this.ExecContext(ctx, tx)
+	if err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			log.Error(err2)
+		}
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -16,7 +16,6 @@ type createDatabaseMaker struct {
 func ifExistsNotExistsBlockMicrosoftCase(part sqlcore.SqlPart,
 	stat *sqlcore.Statement, format *sqlcore.Format, stack *sqlcore.CallStack) (*sqlcore.Statement, error) {
 	partKind := part.GetPartKind()
-	newst := sqlcore.NewStatement(sqlcore.SS_EXEC)
 	ef := sqlexp.Factory()
 	var fnc sqlexp.Expr
 	switch partKind {
@@ -29,9 +28,23 @@ func ifExistsNotExistsBlockMicrosoftCase(part sqlcore.SqlPart,
 		sect := part.(*createTable)
 		objectId := ef.FuncDef(ef.FuncDialectDef(
 			sqldef.DI_MSTSQL, "object_id({})", 1, 2))
-		name := format.FormatTableName(sect.Table.Name)
+		name := format.FormatTableName(sect.getName())
 		fnc = ef.IsNull(ef.Func(objectId, name, "U"))
+	case sqlcore.SPK_CREATE_INDEX:
+		sect := part.(*createIndex)
+		indexId := ef.FuncDef(ef.FuncDialectDef(sqldef.DI_MSTSQL,
+			"indexproperty(object_id({0}), {1}, 'IndexID')", 2, 2))
+		name := format.FormatTableName(sect.Table.Name)
+		fnc = ef.IsNull(ef.Func(indexId, name,
+			sect.Index.GetOrAdviceName(sect.Table)))
+	case sqlcore.SPK_CREATE_VIEW:
+		sect := part.(*createView)
+		objectId := ef.FuncDef(ef.FuncDialectDef(
+			sqldef.DI_MSTSQL, "object_id({})", 1, 2))
+		name := format.FormatObjectName(sect.View.Name)
+		fnc = ef.IsNull(ef.Func(objectId, name, "V"))
 	}
+	newst := sqlcore.NewStatement(sqlcore.SS_EXEC)
 	context := sqlexp.NewExprBuildContext(partKind, sqlcore.SSPK_EXPR1,
 		stack, format, nil)
 	stat2, err := fnc.GetSql(context)
@@ -206,6 +219,8 @@ type CreateTable interface {
 
 type createTable struct {
 	Table *sqldb.TableDef
+	// name of created table, when differs from table definition
+	Name string
 }
 
 // TODO add "check if exists" parameter
@@ -214,6 +229,20 @@ func NewCreateTable(table *sqldb.TableDef) CreateTable {
 	return r
 }
 
+// Create table with structure of table definition under another name.
+// Indexes are not created, since their names might be already in use.
+func NewCreateTableAs(table *sqldb.TableDef, name string) CreateTable {
+	r := &createTable{Table: table, Name: name}
+	return r
+}
+
+func (this *createTable) getName() string {
+	if this.Name != "" {
+		return this.Name
+	}
+	return this.Table.Name
+}
+
 func (this *createTable) getSqlFieldDataType(stat *sqlcore.Statement,
 	format *sqlcore.Format, stack *sqlcore.CallStack, field *sqldb.FieldDef) error {
 	data := field.Data.GetStrTemplate(format.Dialect)
@@ -294,23 +323,28 @@ func bsfr(dataTypes sqldef.DataType, showNullable bool, customAttr1 string,
 
 type BuildSqlFieldVarianceRule struct {
 	PrimaryKeyInline bool
+	// single field foreign keys specified in field definition
+	ForeignKeyInline bool
 	Items            []*BuildSqlFieldRule
 }
 
-func bsfvr(primaryKeyInline bool, items ...*BuildSqlFieldRule) *BuildSqlFieldVarianceRule {
-	btd := &BuildSqlFieldVarianceRule{PrimaryKeyInline: primaryKeyInline, Items: items}
+func bsfvr(primaryKeyInline bool, foreignKeyInline bool,
+	items ...*BuildSqlFieldRule) *BuildSqlFieldVarianceRule {
+	btd := &BuildSqlFieldVarianceRule{PrimaryKeyInline: primaryKeyInline,
+		ForeignKeyInline: foreignKeyInline, Items: items}
 	return btd
 }
 
 func (this *createTable) getBuildSqlFieldVarianceRule(
 	dialect sqldef.Dialect) *BuildSqlFieldVarianceRule {
 	tmplt := map[sqldef.Dialect]*BuildSqlFieldVarianceRule{
-		sqldef.DI_MSTSQL: bsfvr(false, bsfr(sqldef.DT_ALL, true, "", false)),
-		sqldef.DI_PGSQL:  bsfvr(false, bsfr(sqldef.DT_ALL, true, "", false)),
-		sqldef.DI_MYSQL: bsfvr(true, bsfr(sqldef.DT_AUTOINC_INT|sqldef.DT_AUTOINC_INT_BIG,
+		sqldef.DI_MSTSQL: bsfvr(false, false, bsfr(sqldef.DT_ALL, true, "", false)),
+		sqldef.DI_PGSQL:  bsfvr(false, false, bsfr(sqldef.DT_ALL, true, "", false)),
+		// MySQL ignores foreign keys specified in field definition
+		sqldef.DI_MYSQL: bsfvr(true, false, bsfr(sqldef.DT_AUTOINC_INT|sqldef.DT_AUTOINC_INT_BIG,
 			true, "auto_increment", true),
 			bsfr(sqldef.DT_ALL, true, "", true)),
-		sqldef.DI_SQLITE: bsfvr(true, bsfr(sqldef.DT_AUTOINC_INT|sqldef.DT_AUTOINC_INT_BIG,
+		sqldef.DI_SQLITE: bsfvr(true, true, bsfr(sqldef.DT_AUTOINC_INT|sqldef.DT_AUTOINC_INT_BIG,
 			false, "primary key autoincrement", false),
 			bsfr(sqldef.DT_ALL, true, "", true)),
 	}
@@ -321,7 +355,8 @@ func (this *createTable) getBuildSqlFieldVarianceRule(
 }
 
 func (this *createTable) getSqlField(stat *sqlcore.Statement,
-	format *sqlcore.Format, stack *sqlcore.CallStack, field *sqldb.FieldDef) error {
+	format *sqlcore.Format, stack *sqlcore.CallStack, field *sqldb.FieldDef,
+	withKeys bool) error {
 	bsfvr := this.getBuildSqlFieldVarianceRule(format.Dialect)
 	var bsfr *BuildSqlFieldRule
 	if bsfvr != nil {
@@ -353,10 +388,19 @@ func (this *createTable) getSqlField(stat *sqlcore.Statement,
 				stat.WriteString(" ")
 				stat.WriteString(bsfr.CustomAttr1)
 			}
-			if bsfvr.PrimaryKeyInline && bsfr.ShowPrimaryKey &&
+			if withKeys && bsfvr.PrimaryKeyInline && bsfr.ShowPrimaryKey &&
 				field.GetOrAdviceIsPrimaryKey() {
 				stat.WriteString(" primary key")
 			}
+			if withKeys && bsfvr.ForeignKeyInline {
+				for _, fk := range this.Table.ForeignKeys.Items {
+					if len(fk.Fields) == 1 && fk.Fields[0].Name == field.Name {
+						stat.WriteString(" constraint %s ", format.FormatObjectName(
+							fk.GetOrAdviceName(this.Table)))
+						this.getSqlForeignKeyRef(stat, format, fk)
+					}
+				}
+			}
 		}
 	}
 	return nil
@@ -370,7 +414,7 @@ func (this *createTable) buildCreateTableMainSql(maker *createTableMaker,
 		maker.Format.Dialect.In(sqldef.DI_PGSQL|sqldef.DI_MYSQL|sqldef.DI_SQLITE) {
 		stat.WriteString("if not exists ")
 	}
-	name := maker.Format.FormatTableName(this.Table.Name /*, this.Db.Name*/)
+	name := maker.Format.FormatTableName(this.getName() /*, this.Db.Name*/)
 	stat.WriteString("%s (", name)
 	stat.WriteString(maker.Format.SectionDivider)
 	maker.Format.IncIndentLevel()
@@ -381,7 +425,7 @@ func (this *createTable) buildCreateTableMainSql(maker *createTableMaker,
 			stat.WriteString(maker.Format.SectionDivider)
 		}
 		stat.WriteString(maker.Format.GetLeadingSpace())
-		err := this.getSqlField(stat, maker.Format, stack, field)
+		err := this.getSqlField(stat, maker.Format, stack, field, true)
 		if err != nil {
 			maker.Format.DecIndentLevel()
 			return err
@@ -417,44 +461,227 @@ func (this *createTable) buildPrimaryKeySql(maker *createTableMaker,
 		log.Warn(f("No primary key defined or "+
 			"can be adviced for table \"%s\"", this.Table.Name))
 	}
-	stat.WriteString(")")
 	maker.Format.DecIndentLevel()
 	return nil
 }
 
-func (this *createTable) buildIndexesSql(maker *createTableMaker,
+// Write referenced table with fields and actions of foreign key:
+//
+//	references table2 (column1, ...) on delete ... on update ...
+func (this *createTable) getSqlForeignKeyRef(stat *sqlcore.Statement,
+	format *sqlcore.Format, fk *sqldb.ForeignKeyDef) {
+	stat.WriteString("references %s (", format.FormatTableName(fk.RefTable.Name))
+	for i, field := range fk.GetOrAdviceRefFields() {
+		if i > 0 {
+			stat.WriteString(", ")
+		}
+		stat.WriteString(format.FormatObjectName(field.Name))
+	}
+	stat.WriteString(")")
+	actions := []struct {
+		Event  string
+		Action sqldb.ForeignKeyAction
+	}{{"delete", fk.OnDelete}, {"update", fk.OnUpdate}}
+	for _, item := range actions {
+		action := item.Action
+		// Microsoft T-SQL doesn't support "restrict" action,
+		// which is equivalent to "no action" there
+		if action == sqldb.FKA_RESTRICT && format.Dialect == sqldef.DI_MSTSQL {
+			action = sqldb.FKA_NO_ACTION
+		}
+		if action != sqldb.FKA_NO_ACTION {
+			stat.WriteString(" on %s %v", item.Event, action)
+		}
+	}
+}
+
+// Write foreign key constraint:
+//
+//	constraint fk1 foreign key (column1, ...) references ...
+func (this *createTable) getSqlForeignKey(stat *sqlcore.Statement,
+	format *sqlcore.Format, fk *sqldb.ForeignKeyDef) {
+	stat.WriteString("constraint %s foreign key (",
+		format.FormatObjectName(fk.GetOrAdviceName(this.Table)))
+	for i, field := range fk.Fields {
+		if i > 0 {
+			stat.WriteString(", ")
+		}
+		stat.WriteString(format.FormatObjectName(field.Name))
+	}
+	stat.WriteString(") ")
+	this.getSqlForeignKeyRef(stat, format, fk)
+}
+
+// Write unique constraint:
+//
+//	constraint unique1 unique (column1, ...)
+func (this *createTable) getSqlUnique(stat *sqlcore.Statement,
+	format *sqlcore.Format, unique *sqldb.UniqueDef) {
+	stat.WriteString("constraint %s unique (",
+		format.FormatObjectName(unique.GetOrAdviceName(this.Table)))
+	for i, field := range unique.Fields {
+		if i > 0 {
+			stat.WriteString(", ")
+		}
+		stat.WriteString(format.FormatObjectName(field.Name))
+	}
+	stat.WriteString(")")
+}
+
+// Write check constraint:
+//
+//	constraint check1 check (...)
+func (this *createTable) getSqlCheck(stat *sqlcore.Statement,
+	format *sqlcore.Format, stack *sqlcore.CallStack, check *sqldb.CheckDef) error {
+	// check condition can't be parametrized
+	format2 := *format
+	format2.AddOptions(sqlcore.BO_INLINE)
+	entries := sqlexp.NewQueryEntries()
+	entries.AddEntry(this.Table)
+	context := sqlexp.NewExprBuildContext(sqlcore.SPK_CREATE_TABLE,
+		sqlcore.SSPK_EXPR1, stack, &format2, entries)
+	stat2, err := check.Cond.GetSql(context)
+	if err != nil {
+		return err
+	}
+	stat.WriteString("constraint %s ",
+		format.FormatObjectName(check.GetOrAdviceName(this.Table)))
+	stat.AppendStatPartsFormat("check (%s)", stat2)
+	return nil
+}
+
+func (this *createTable) getSqlConstraint(stat *sqlcore.Statement,
+	format *sqlcore.Format, stack *sqlcore.CallStack,
+	constraint sqldb.ConstraintDef) error {
+	switch item := constraint.(type) {
+	case *sqldb.ForeignKeyDef:
+		this.getSqlForeignKey(stat, format, item)
+	case *sqldb.UniqueDef:
+		this.getSqlUnique(stat, format, item)
+	case *sqldb.CheckDef:
+		return this.getSqlCheck(stat, format, stack, item)
+	default:
+		return e("Unexpected constraint \"%s\" of table \"%s\": %v",
+			constraint.GetOrAdviceName(this.Table), this.Table.Name, constraint)
+	}
+	return nil
+}
+
+func (this *createTable) buildConstraintsSql(maker *createTableMaker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
-	if len(this.Table.Indexes.Items) > 0 {
-		//        stat.WriteString(";")
-		for _, index := range this.Table.Indexes.Items {
-			if len(index.Items) > 0 {
-				//                stat.WriteString(maker.Format.sectionDivider)
-				stat.WriteString(maker.Format.GetLeadingSpace())
-				stat.WriteString(f("create index %s", maker.Format.
-					FormatObjectName(index.Name)))
-				stat.WriteString(maker.Format.SectionDivider)
-				maker.Format.IncIndentLevel()
-				stat.WriteString(maker.Format.GetLeadingSpace())
-				stat.WriteString(f("on %s (", maker.Format.
-					FormatObjectName(this.Table.Name)))
-				for i, field := range index.Items {
-					if i > 0 {
-						stat.WriteString(",")
-					}
-					stat.WriteString(f("%s", maker.Format.
-						FormatObjectName(field.Name)))
-				}
-				stat.WriteString(")")
-				maker.Format.DecIndentLevel()
-			}
+	maker.Format.IncIndentLevel()
+	defer maker.Format.DecIndentLevel()
+	bsfvr := this.getBuildSqlFieldVarianceRule(maker.Format.Dialect)
+	var constraints []sqldb.ConstraintDef
+	for _, fk := range this.Table.ForeignKeys.Items {
+		if bsfvr.ForeignKeyInline && len(fk.Fields) == 1 {
+			continue
+		}
+		constraints = append(constraints, fk)
+	}
+	for _, unique := range this.Table.Uniques.Items {
+		constraints = append(constraints, unique)
+	}
+	for _, check := range this.Table.Checks.Items {
+		constraints = append(constraints, check)
+	}
+	for _, constraint := range constraints {
+		stat.WriteString(",")
+		stat.WriteString(maker.Format.SectionDivider)
+		stat.WriteString(maker.Format.GetLeadingSpace())
+		err := this.getSqlConstraint(stat, maker.Format, stack, constraint)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// Write index inside of "create table" statement, which is MySQL
+// specific syntax (covered by "if not exists" option of table):
+//
+//	[unique] index index1 (column1 [desc], ...)
+func (this *createTable) getSqlIndexInline(stat *sqlcore.Statement,
+	format *sqlcore.Format, index *sqldb.IndexDef) error {
+	name := index.GetOrAdviceName(this.Table)
+	if index.Filter != nil {
+		return e("%v dialect doesn't support partial indexes: \"%s\"",
+			format.Dialect, name)
+	}
+	if index.IsUnique {
+		stat.WriteString("unique ")
+	}
+	stat.WriteString("index %s (", format.FormatObjectName(name))
+	for i, item := range index.Fields {
+		if i > 0 {
+			stat.WriteString(", ")
+		}
+		stat.WriteString(format.FormatObjectName(item.Field.Name))
+		if item.Descending {
+			stat.WriteString(" desc")
+		}
+	}
+	stat.WriteString(")")
+	return nil
+}
+
+func (this *createTable) buildIndexesInlineSql(maker *createTableMaker,
+	stat *sqlcore.Statement) error {
+	maker.Format.IncIndentLevel()
+	defer maker.Format.DecIndentLevel()
+	for _, index := range this.Table.Indexes.Items {
+		stat.WriteString(",")
+		stat.WriteString(maker.Format.SectionDivider)
+		stat.WriteString(maker.Format.GetLeadingSpace())
+		err := this.getSqlIndexInline(stat, maker.Format, index)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *createTable) buildIndexesSql(maker *createTableMaker,
+	stack *sqlcore.CallStack) error {
+	for _, index := range this.Table.Indexes.Items {
+		stat := sqlcore.NewStatement(sqlcore.SS_EXEC)
+		sect := &createIndex{Table: this.Table, Index: index}
+		err := sect.buildCreateIndexSql(maker.Format, stat, stack)
+		if err != nil {
+			return err
+		}
+		maker.Batch.Add(stat)
+	}
+	return nil
+}
+
 func (this *createTable) preBuildCreateTableSql(maker *createTableMaker,
 	stack *sqlcore.CallStack) error {
 	stat := maker.Batch.Last()
+	for _, fk := range this.Table.ForeignKeys.Items {
+		err := fk.Validate(this.Table)
+		if err != nil {
+			return err
+		}
+	}
+	for _, index := range this.Table.Indexes.Items {
+		err := index.Validate(this.Table)
+		if err != nil {
+			return err
+		}
+	}
+	for _, unique := range this.Table.Uniques.Items {
+		err := unique.Validate(this.Table)
+		if err != nil {
+			return err
+		}
+	}
+	for _, check := range this.Table.Checks.Items {
+		err := check.Validate(this.Table)
+		if err != nil {
+			return err
+		}
+	}
 	// build create statement itself
 	err := this.buildCreateTableMainSql(maker, stat, stack)
 	if err != nil {
@@ -465,18 +692,29 @@ func (this *createTable) preBuildCreateTableSql(maker *createTableMaker,
 	if err != nil {
 		return err
 	}
-	// add indexes
-	var stat2 *sqlcore.Statement
-	if len(this.Table.Indexes.Items) > 0 {
-		stat2 = sqlcore.NewStatement(sqlcore.SS_EXEC)
-		err = this.buildIndexesSql(maker, stat2, stack)
+	// add foreign keys, unique and check constraints
+	err = this.buildConstraintsSql(maker, stat, stack)
+	if err != nil {
+		return err
+	}
+	// table created under another name can't reuse index names
+	if this.Name != "" {
+		stat.WriteString(")")
+		return nil
+	}
+	// MySQL lacks "if not exists" option for "create index",
+	// so indexes are defined inside of "create table" statement
+	if maker.Format.Dialect == sqldef.DI_MYSQL {
+		err = this.buildIndexesInlineSql(maker, stat)
 		if err != nil {
 			return err
 		}
-		maker.Batch.Add(stat2)
+		stat.WriteString(")")
+		return nil
 	}
-	// join statement if necessary
-	err = maker.Batch.Join(maker.Format)
+	stat.WriteString(")")
+	// add indexes as separate statements
+	err = this.buildIndexesSql(maker, stack)
 	return err
 }
 
@@ -496,14 +734,25 @@ func (this *createTable) buildCreateTableSql(maker *createTableMaker,
 		return err
 	}
 	if maker.Format.DoIfObjectExistsNotExists() &&
-		maker.Format.Dialect == sqldef.DI_MSTSQL && len(maker.Batch.Items) == 1 {
-		stat := maker.Batch.Last()
+		maker.Format.Dialect == sqldef.DI_MSTSQL {
+		stat := maker.Batch.Items[0]
 		newstat, err := ifExistsNotExistsBlockMicrosoftCase(this,
 			stat, maker.Format, stack)
 		if err != nil {
 			return err
 		}
 		maker.Batch.Replace(stat, newstat)
+		// indexes checked one by one, since table might exist already
+		for i, stat := range maker.Batch.Items[1:] {
+			sect := &createIndex{Table: this.Table,
+				Index: this.Table.Indexes.Items[i]}
+			newstat, err := ifExistsNotExistsBlockMicrosoftCase(sect,
+				stat, maker.Format, stack)
+			if err != nil {
+				return err
+			}
+			maker.Batch.Replace(stat, newstat)
+		}
 	}
 	return nil
 }
@@ -524,3 +773,29 @@ func (this *createTable) GetPartKind() sqlcore.SqlPartKind {
 func (this *createTable) GetParent() sqlcore.SqlPart {
 	return nil
 }
+
+// Write column definition the same way as it's done
+// in "create table" statement. When withKeys is false, primary
+// and foreign keys specified in column definition are omitted.
+func BuildSqlField(stat *sqlcore.Statement, format *sqlcore.Format,
+	stack *sqlcore.CallStack, table *sqldb.TableDef, field *sqldb.FieldDef,
+	withKeys bool) error {
+	sect := &createTable{Table: table}
+	return sect.getSqlField(stat, format, stack, field, withKeys)
+}
+
+// Write column data type.
+func BuildSqlFieldDataType(stat *sqlcore.Statement, format *sqlcore.Format,
+	stack *sqlcore.CallStack, field *sqldb.FieldDef) error {
+	sect := &createTable{}
+	return sect.getSqlFieldDataType(stat, format, stack, field)
+}
+
+// Write table constraint the same way as it's done
+// in "create table" statement.
+func BuildSqlConstraint(stat *sqlcore.Statement, format *sqlcore.Format,
+	stack *sqlcore.CallStack, table *sqldb.TableDef,
+	constraint sqldb.ConstraintDef) error {
+	sect := &createTable{Table: table}
+	return sect.getSqlConstraint(stat, format, stack, constraint)
+}
@@ -0,0 +1,162 @@
+package sqlcreate
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+type createIndexMaker struct {
+	Format *sqlcore.Format
+	Batch  *sqlcore.StatementBatch
+}
+
+func (this *createIndexMaker) buildCreateIndexSql(sect *createIndex,
+	stack *sqlcore.CallStack) error {
+	if this.Format.DoIfObjectExistsNotExists() &&
+		this.Format.Dialect == sqldef.DI_MSTSQL {
+		this.Format.IncIndentLevel()
+		defer this.Format.DecIndentLevel()
+	}
+	return sect.buildCreateIndexSql(this.Format, this.Batch.Last(), stack)
+}
+
+func (this *createIndexMaker) runMaker(direct bool,
+	part sqlcore.SqlPart, stack *sqlcore.CallStack) error {
+	if direct == false {
+		var err error
+		switch part.GetPartKind() {
+		case sqlcore.SPK_CREATE_INDEX:
+			sect := part.(*createIndex)
+			err = this.buildCreateIndexSql(sect, stack)
+			if err != nil {
+				return err
+			}
+			if this.Format.DoIfObjectExistsNotExists() &&
+				this.Format.Dialect == sqldef.DI_MSTSQL {
+				stat := this.Batch.Last()
+				newstat, err := ifExistsNotExistsBlockMicrosoftCase(
+					part, stat, this.Format, stack)
+				if err != nil {
+					return err
+				}
+				this.Batch.Replace(stat, newstat)
+			}
+		default:
+			err = e("Unexpected section during generating "+
+				"\"create index\" statement: %v", part)
+		}
+		return err
+	}
+	return nil
+}
+
+func (this *createIndexMaker) BuildSql(part sqlcore.SqlPart,
+	format *sqlcore.Format) error {
+	f := *format
+	this.Format = &f
+	this.Batch = sqlcore.NewStatementBatch()
+	this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
+	return sqlcore.IterateSqlParents(false, part, this.runMaker)
+}
+
+type CreateIndex interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+}
+
+type createIndex struct {
+	Table *sqldb.TableDef
+	Index *sqldb.IndexDef
+}
+
+func NewCreateIndex(table *sqldb.TableDef, index *sqldb.IndexDef) CreateIndex {
+	r := &createIndex{Table: table, Index: index}
+	return r
+}
+
+// Build "create index" statement:
+//
+//	create [unique] index [if not exists] index1
+//	    on table1 (column1 [desc], ...)
+//	    [where ...]
+func (this *createIndex) buildCreateIndexSql(format *sqlcore.Format,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	err := this.Index.Validate(this.Table)
+	if err != nil {
+		return err
+	}
+	name := this.Index.GetOrAdviceName(this.Table)
+	stat.WriteString(format.GetLeadingSpace())
+	stat.WriteString("create ")
+	if this.Index.IsUnique {
+		stat.WriteString("unique ")
+	}
+	stat.WriteString("index ")
+	if format.DoIfObjectExistsNotExists() {
+		switch format.Dialect {
+		case sqldef.DI_PGSQL, sqldef.DI_SQLITE:
+			stat.WriteString("if not exists ")
+		case sqldef.DI_MYSQL:
+			// statement can't be run repeatedly, so refuse
+			// to generate it instead of failing on second run
+			return e("%v dialect doesn't support \"IF NOT EXISTS\" option "+
+				"for \"create index\" statement: \"%s\"", format.Dialect, name)
+		}
+	}
+	stat.WriteString(format.FormatObjectName(name))
+	stat.WriteString(format.SectionDivider)
+	format.IncIndentLevel()
+	defer format.DecIndentLevel()
+	stat.WriteString(format.GetLeadingSpace())
+	stat.WriteString("on %s (", format.FormatTableName(this.Table.Name))
+	for i, item := range this.Index.Fields {
+		if i > 0 {
+			stat.WriteString(", ")
+		}
+		stat.WriteString(format.FormatObjectName(item.Field.Name))
+		if item.Descending {
+			stat.WriteString(" desc")
+		}
+	}
+	stat.WriteString(")")
+	if this.Index.Filter != nil {
+		if format.Dialect == sqldef.DI_MYSQL {
+			return e("%v dialect doesn't support partial indexes: \"%s\"",
+				format.Dialect, name)
+		}
+		// index condition can't be parametrized
+		format2 := *format
+		format2.AddOptions(sqlcore.BO_INLINE)
+		entries := sqlexp.NewQueryEntries()
+		entries.AddEntry(this.Table)
+		context := sqlexp.NewExprBuildContext(sqlcore.SPK_CREATE_INDEX,
+			sqlcore.SSPK_EXPR1, stack, &format2, entries)
+		stat2, err := this.Index.Filter.GetSql(context)
+		if err != nil {
+			return err
+		}
+		stat.WriteString(format.SectionDivider)
+		stat.WriteString(format.GetLeadingSpace())
+		stat.AppendStatPartsFormat("where %s", stat2)
+	}
+	return nil
+}
+
+func (this *createIndex) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &createIndexMaker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, nil
+}
+
+func (this *createIndex) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_CREATE_INDEX
+}
+
+func (this *createIndex) GetParent() sqlcore.SqlPart {
+	return nil
+}
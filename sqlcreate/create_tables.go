@@ -0,0 +1,37 @@
+package sqlcreate
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+)
+
+type CreateTables interface {
+	sqlcore.SqlReady
+}
+
+// Create set of tables in order of dependency
+// specified by foreign keys.
+type createTables struct {
+	Tables []*sqldb.TableDef
+}
+
+func NewCreateTables(tables ...*sqldb.TableDef) CreateTables {
+	r := &createTables{Tables: tables}
+	return r
+}
+
+func (this *createTables) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	tables, err := sqldb.SortTablesByDependency(this.Tables)
+	if err != nil {
+		return nil, err
+	}
+	batch := sqlcore.NewStatementBatch()
+	for _, table := range tables {
+		batch2, err := NewCreateTable(table).GetSql(format)
+		if err != nil {
+			return nil, err
+		}
+		batch.Items = append(batch.Items, batch2.Items...)
+	}
+	return batch, nil
+}
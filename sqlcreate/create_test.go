@@ -0,0 +1,49 @@
+package sqlcreate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+)
+
+func TestCreateTableIndexesIfNotExists(t *testing.T) {
+	table := sqldb.Table("emp")
+	table.Fields.AddAutoinc("id")
+	dept := table.Fields.AddInt("dept")
+	name := table.Fields.AddUnicodeVariable("name", 50)
+	table.Indexes.AddIndex("", dept)
+	table.Indexes.AddUniqueIndex("UX_emp_name", name)
+	cases := []struct {
+		dialect sqldef.Dialect
+		count   int
+		want    string
+	}{
+		{sqldef.DI_PGSQL, 3, `create index if not exists "IX_emp_dept"`},
+		{sqldef.DI_MYSQL, 1, "unique index `UX_emp_name` (`name`))"},
+		{sqldef.DI_SQLITE, 3, `create unique index if not exists UX_emp_name`},
+	}
+	for _, c := range cases {
+		format := sqlcore.NewFormat(c.dialect)
+		format.AddOptions(sqlcore.BO_DO_IF_OBJECT_EXISTS_NOT_EXISTS)
+		batch, err := NewCreateTable(table).GetSql(format)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", c.dialect, err)
+			continue
+		}
+		if len(batch.Items) != c.count {
+			t.Errorf("%v: expected %d statements, got %d",
+				c.dialect, c.count, len(batch.Items))
+		}
+		var sqls []string
+		for _, stat := range batch.Items {
+			sqls = append(sqls, stat.Sql())
+		}
+		sql := strings.Join(sqls, "\n")
+		if !strings.Contains(sql, c.want) {
+			t.Errorf("%v: expected %q in:\n%s", c.dialect, c.want, sql)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package sqlcreate
+
+import (
+	"strings"
+
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+)
+
+type createViewMaker struct {
+	Format *sqlcore.Format
+	Batch  *sqlcore.StatementBatch
+}
+
+func (this *createViewMaker) buildCreateViewSql(sect *createView,
+	stack *sqlcore.CallStack) error {
+	if this.Format.DoIfObjectExistsNotExists() && !sect.Replace &&
+		this.Format.Dialect == sqldef.DI_MSTSQL {
+		this.Format.IncIndentLevel()
+		defer this.Format.DecIndentLevel()
+	}
+	// SQLite doesn't support "or replace" option,
+	// so view is dropped beforehand
+	if sect.Replace && this.Format.Dialect == sqldef.DI_SQLITE {
+		stat := this.Batch.Last()
+		stat.WriteString(this.Format.GetLeadingSpace())
+		stat.WriteString("drop view if exists %s",
+			this.Format.FormatObjectName(sect.View.Name))
+		this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
+	}
+	stat := this.Batch.Last()
+	err := sect.buildCreateViewSql(this.Format, stat, stack)
+	if err != nil {
+		return err
+	}
+	if this.Format.DoIfObjectExistsNotExists() && !sect.Replace &&
+		this.Format.Dialect == sqldef.DI_MSTSQL {
+		// "create view" must be the only statement in a batch,
+		// so run it as dynamic sql; unicode literal keeps
+		// non-ASCII characters of view definition
+		stat2 := sqlcore.NewStatement(sqlcore.SS_EXEC)
+		stat2.WriteString(this.Format.GetLeadingSpace())
+		stat2.WriteString("exec(N'%s')", strings.Replace(
+			strings.TrimSpace(stat.Sql()), "'", "''", -1))
+		this.Batch.Replace(stat, stat2)
+	}
+	return nil
+}
+
+func (this *createViewMaker) runMaker(direct bool,
+	part sqlcore.SqlPart, stack *sqlcore.CallStack) error {
+	if direct == false {
+		var err error
+		switch part.GetPartKind() {
+		case sqlcore.SPK_CREATE_VIEW:
+			sect := part.(*createView)
+			err = this.buildCreateViewSql(sect, stack)
+			if err != nil {
+				return err
+			}
+			if this.Format.DoIfObjectExistsNotExists() && !sect.Replace &&
+				this.Format.Dialect == sqldef.DI_MSTSQL {
+				stat := this.Batch.Last()
+				newstat, err := ifExistsNotExistsBlockMicrosoftCase(
+					part, stat, this.Format, stack)
+				if err != nil {
+					return err
+				}
+				this.Batch.Replace(stat, newstat)
+			}
+		default:
+			err = e("Unexpected section during generating "+
+				"\"create view\" statement: %v", part)
+		}
+		return err
+	}
+	return nil
+}
+
+func (this *createViewMaker) BuildSql(part sqlcore.SqlPart,
+	format *sqlcore.Format) error {
+	f := *format
+	this.Format = &f
+	this.Batch = sqlcore.NewStatementBatch()
+	this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
+	return sqlcore.IterateSqlParents(false, part, this.runMaker)
+}
+
+type CreateView interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+	OrReplace() CreateView
+}
+
+type createView struct {
+	View    *sqldb.ViewDef
+	Replace bool
+}
+
+func NewCreateView(view *sqldb.ViewDef) CreateView {
+	r := &createView{View: view}
+	return r
+}
+
+// Replace existing view definition.
+func (this *createView) OrReplace() CreateView {
+	r := &createView{View: this.View, Replace: true}
+	return r
+}
+
+// Build "create view" statement:
+//
+//	create [or replace] view [if not exists] view1 [(column1, ...)] as
+//	select ...
+func (this *createView) buildCreateViewSql(format *sqlcore.Format,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	if this.View.Query == nil {
+		return e("Statement of view \"%s\" is nil", this.View.Name)
+	}
+	if format.ColumnNameAndCountValidationIsOn() && len(this.View.Columns) > 0 {
+		query, ok := this.View.Query.(sqlcore.Query)
+		if !ok {
+			return e("Statement of view \"%s\" is not a query", this.View.Name)
+		}
+		c, err := query.GetColumnCount()
+		if err != nil {
+			return err
+		}
+		if c != len(this.View.Columns) {
+			return e("Column count doesn't match in view "+
+				"\"%s\": %d <> %d", this.View.Name, len(this.View.Columns), c)
+		}
+	}
+	stat.WriteString(format.GetLeadingSpace())
+	stat.WriteString("create ")
+	if this.Replace {
+		switch format.Dialect {
+		case sqldef.DI_PGSQL, sqldef.DI_MYSQL:
+			stat.WriteString("or replace ")
+		case sqldef.DI_MSTSQL:
+			stat.WriteString("or alter ")
+		}
+	}
+	stat.WriteString("view ")
+	if format.DoIfObjectExistsNotExists() && !this.Replace {
+		switch format.Dialect {
+		case sqldef.DI_SQLITE:
+			stat.WriteString("if not exists ")
+		case sqldef.DI_PGSQL, sqldef.DI_MYSQL:
+			log.Warnf("%v dialect doesn't support \"IF NOT EXISTS\" option "+
+				"for \"create view\" statement", format.Dialect)
+		}
+	}
+	stat.WriteString(format.FormatObjectName(this.View.Name))
+	if len(this.View.Columns) > 0 {
+		stat.WriteString(" (")
+		for i, column := range this.View.Columns {
+			if i > 0 {
+				stat.WriteString(", ")
+			}
+			stat.WriteString(format.FormatObjectName(column))
+		}
+		stat.WriteString(")")
+	}
+	stat.WriteString(" as")
+	stat.WriteString(format.SectionDivider)
+	// view can't be parametrized
+	format2 := *format
+	format2.AddOptions(sqlcore.BO_INLINE)
+	batch, err := this.View.Query.GetSql(&format2)
+	if err != nil {
+		return err
+	}
+	if len(batch.Items) > 1 {
+		return e("Can't use multiple sql statments in view \"%s\": %v",
+			this.View.Name, batch)
+	}
+	stat.AppendStatPart(batch.Items[0])
+	return nil
+}
+
+func (this *createView) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &createViewMaker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, err
+}
+
+func (this *createView) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_CREATE_VIEW
+}
+
+func (this *createView) GetParent() sqlcore.SqlPart {
+	return nil
+}
@@ -11,6 +11,21 @@ type DefaultDef struct {
 	Value sqlexp.Expr
 }
 
+// Create default value definition, where value
+// either expression or constant. Nil value stands for null.
+func NewDefaultDef(value interface{}) *DefaultDef {
+	var expr sqlexp.Expr
+	switch value.(type) {
+	case sqlexp.Expr:
+		expr = value.(sqlexp.Expr)
+	case nil:
+		expr = nil
+	default:
+		expr = &sqlexp.TokenValue{Value: value}
+	}
+	return &DefaultDef{On: true, Value: expr}
+}
+
 type FieldDef struct {
 	Name         string
 	Data         *sqldef.DataDef
@@ -30,16 +45,7 @@ func (this *FieldDef) GetName() string {
 }
 
 func (this *FieldDef) DefaultValue(value interface{}) *FieldDef {
-	var expr sqlexp.Expr
-	switch value.(type) {
-	case sqlexp.Expr:
-		expr = value.(sqlexp.Expr)
-	case nil:
-		expr = nil
-	default:
-		expr = &sqlexp.TokenValue{Value: value}
-	}
-	this.Default = &DefaultDef{On: true, Value: expr}
+	this.Default = NewDefaultDef(value)
 	return this
 }
 
@@ -150,23 +156,305 @@ func (this *FieldCollection) AddField(field *FieldDef) {
 	this.Items = append(this.Items, field)
 }
 
+type IndexFieldDef struct {
+	Field      *FieldDef
+	Descending bool
+}
+
+type IndexDef struct {
+	Name     string
+	Fields   []*IndexFieldDef
+	IsUnique bool
+	// condition of partial (filtered) index,
+	// should refer to fields of the table only
+	Filter sqlexp.Expr
+}
+
+func (this *IndexDef) Unique() *IndexDef {
+	this.IsUnique = true
+	return this
+}
+
+// Mark fields of index to be sorted in descending order.
+func (this *IndexDef) Desc(fields ...*FieldDef) *IndexDef {
+	for _, field := range fields {
+		for _, item := range this.Fields {
+			if item.Field == field {
+				item.Descending = true
+			}
+		}
+	}
+	return this
+}
+
+// Make index partial, which covers only rows satisfying condition.
+func (this *IndexDef) Where(cond sqlexp.Expr) *IndexDef {
+	this.Filter = cond
+	return this
+}
+
+func (this *IndexDef) GetOrAdviceName(table *TableDef) string {
+	if this.Name != "" {
+		return this.Name
+	}
+	name := f("IX_%s", table.Name)
+	if this.IsUnique {
+		name = f("UX_%s", table.Name)
+	}
+	for _, item := range this.Fields {
+		name += f("_%s", item.Field.Name)
+	}
+	return name
+}
+
+// Verify that index fields belong to the table.
+func (this *IndexDef) Validate(table *TableDef) error {
+	name := this.GetOrAdviceName(table)
+	if len(this.Fields) == 0 {
+		return e("No fields specified for index \"%s\"", name)
+	}
+	for _, item := range this.Fields {
+		if table.Fields.Find(item.Field.Name) == nil {
+			return e("Field \"%s\" of index \"%s\" doesn't "+
+				"belong to table \"%s\"", item.Field.Name, name, table.Name)
+		}
+	}
+	return nil
+}
+
 type IndexesDef struct {
-	Items []*FieldCollection
+	Items []*IndexDef
 }
 
-func (id *IndexesDef) AddIndex(name string,
-	fields ...*FieldDef) *FieldCollection {
-	fc := &FieldCollection{Name: name, Items: fields}
-	id.Items = append(id.Items, fc)
-	return fc
+func (this *IndexesDef) AddIndex(name string,
+	fields ...*FieldDef) *IndexDef {
+	index := &IndexDef{Name: name}
+	for _, field := range fields {
+		index.Fields = append(index.Fields, &IndexFieldDef{Field: field})
+	}
+	this.Items = append(this.Items, index)
+	return index
+}
+
+func (this *IndexesDef) AddUniqueIndex(name string,
+	fields ...*FieldDef) *IndexDef {
+	return this.AddIndex(name, fields...).Unique()
+}
+
+// Table constraint: foreign key, unique or check one.
+type ConstraintDef interface {
+	GetOrAdviceName(table *TableDef) string
+	Validate(table *TableDef) error
+}
+
+type UniqueDef struct {
+	Name   string
+	Fields []*FieldDef
+}
+
+func (this *UniqueDef) GetOrAdviceName(table *TableDef) string {
+	if this.Name != "" {
+		return this.Name
+	}
+	name := f("UQ_%s", table.Name)
+	for _, field := range this.Fields {
+		name += f("_%s", field.Name)
+	}
+	return name
+}
+
+// Verify that unique constraint fields belong to the table.
+func (this *UniqueDef) Validate(table *TableDef) error {
+	name := this.GetOrAdviceName(table)
+	if len(this.Fields) == 0 {
+		return e("No fields specified for unique constraint \"%s\"", name)
+	}
+	for _, field := range this.Fields {
+		if table.Fields.Find(field.Name) == nil {
+			return e("Field \"%s\" of unique constraint \"%s\" doesn't "+
+				"belong to table \"%s\"", field.Name, name, table.Name)
+		}
+	}
+	return nil
+}
+
+type UniquesDef struct {
+	Items []*UniqueDef
+}
+
+func (this *UniquesDef) AddUnique(name string,
+	fields ...*FieldDef) *UniqueDef {
+	unique := &UniqueDef{Name: name, Fields: fields}
+	this.Items = append(this.Items, unique)
+	return unique
+}
+
+type CheckDef struct {
+	Name string
+	// condition should refer to fields of the table only
+	Cond sqlexp.Expr
+}
+
+func (this *CheckDef) GetOrAdviceName(table *TableDef) string {
+	if this.Name != "" {
+		return this.Name
+	}
+	name := f("CK_%s", table.Name)
+	if this.Cond != nil {
+		for _, field := range this.Cond.CollectFields() {
+			name += f("_%s", field.Name)
+		}
+	}
+	return name
+}
+
+// Verify that fields used in check condition belong to the table.
+func (this *CheckDef) Validate(table *TableDef) error {
+	name := this.GetOrAdviceName(table)
+	if this.Cond == nil {
+		return e("No condition specified for check constraint \"%s\"", name)
+	}
+	for _, field := range this.Cond.CollectFields() {
+		tableBased, entry := field.DataSource.IsTableBased()
+		if !tableBased || entry.GetName() != table.Name {
+			objstr, err := sqlexp.FormatPrettyDataSource(field.DataSource,
+				false, nil)
+			if err != nil {
+				return err
+			}
+			return e("Field \"%s\" of check constraint \"%s\" is associated "+
+				"with %s, but should refer to table \"%s\"", field.Name,
+				name, objstr, table.Name)
+		}
+		if table.Fields.Find(field.Name) == nil {
+			return e("Field \"%s\" of check constraint \"%s\" doesn't "+
+				"belong to table \"%s\"", field.Name, name, table.Name)
+		}
+	}
+	return nil
+}
+
+type ChecksDef struct {
+	Items []*CheckDef
+}
+
+func (this *ChecksDef) AddCheck(name string, cond sqlexp.Expr) *CheckDef {
+	check := &CheckDef{Name: name, Cond: cond}
+	this.Items = append(this.Items, check)
+	return check
+}
+
+type ForeignKeyAction int
+
+const (
+	FKA_NO_ACTION ForeignKeyAction = iota
+	FKA_RESTRICT
+	FKA_CASCADE
+	FKA_SET_NULL
+	FKA_SET_DEFAULT
+)
+
+func (this ForeignKeyAction) String() string {
+	strs := map[ForeignKeyAction]string{
+		FKA_NO_ACTION:   "no action",
+		FKA_RESTRICT:    "restrict",
+		FKA_CASCADE:     "cascade",
+		FKA_SET_NULL:    "set null",
+		FKA_SET_DEFAULT: "set default",
+	}
+	return strs[this]
+}
+
+type ForeignKeyDef struct {
+	Name   string
+	Fields []*FieldDef
+	// referenced table and fields; when no fields
+	// specified, primary key of referenced table used
+	RefTable  *TableDef
+	RefFields []*FieldDef
+	OnDelete  ForeignKeyAction
+	OnUpdate  ForeignKeyAction
+}
+
+func (this *ForeignKeyDef) OnDeleteDo(action ForeignKeyAction) *ForeignKeyDef {
+	this.OnDelete = action
+	return this
+}
+
+func (this *ForeignKeyDef) OnUpdateDo(action ForeignKeyAction) *ForeignKeyDef {
+	this.OnUpdate = action
+	return this
+}
+
+func (this *ForeignKeyDef) GetOrAdviceName(table *TableDef) string {
+	if this.Name != "" {
+		return this.Name
+	}
+	name := f("FK_%s", table.Name)
+	for _, field := range this.Fields {
+		name += f("_%s", field.Name)
+	}
+	return name
+}
+
+func (this *ForeignKeyDef) GetOrAdviceRefFields() []*FieldDef {
+	if len(this.RefFields) > 0 || this.RefTable == nil {
+		return this.RefFields
+	}
+	return this.RefTable.GetOrAdvicePrimaryKey().Items
+}
+
+// Verify that foreign key fields belong to corresponding tables.
+func (this *ForeignKeyDef) Validate(table *TableDef) error {
+	name := this.GetOrAdviceName(table)
+	if len(this.Fields) == 0 {
+		return e("No fields specified for foreign key \"%s\"", name)
+	}
+	if this.RefTable == nil {
+		return e("Referenced table of foreign key \"%s\" is nil", name)
+	}
+	for _, field := range this.Fields {
+		if table.Fields.Find(field.Name) == nil {
+			return e("Field \"%s\" of foreign key \"%s\" doesn't "+
+				"belong to table \"%s\"", field.Name, name, table.Name)
+		}
+	}
+	refFields := this.GetOrAdviceRefFields()
+	for _, field := range refFields {
+		if this.RefTable.Fields.Find(field.Name) == nil {
+			return e("Field \"%s\" of foreign key \"%s\" doesn't "+
+				"belong to referenced table \"%s\"", field.Name, name,
+				this.RefTable.Name)
+		}
+	}
+	if len(this.Fields) != len(refFields) {
+		return e("Field count doesn't match referenced field count "+
+			"in foreign key \"%s\": %d <> %d", name, len(this.Fields),
+			len(refFields))
+	}
+	return nil
+}
+
+type ForeignKeysDef struct {
+	Items []*ForeignKeyDef
+}
+
+func (this *ForeignKeysDef) AddForeignKey(name string, fields []*FieldDef,
+	refTable *TableDef, refFields ...*FieldDef) *ForeignKeyDef {
+	fk := &ForeignKeyDef{Name: name, Fields: fields,
+		RefTable: refTable, RefFields: refFields}
+	this.Items = append(this.Items, fk)
+	return fk
 }
 
-// TODO implement Indexes
 type TableDef struct {
 	Name           string
 	Fields         FieldsDef
 	PrimaryKeyName string
 	Indexes        IndexesDef
+	ForeignKeys    ForeignKeysDef
+	Uniques        UniquesDef
+	Checks         ChecksDef
 }
 
 func Table(name string) *TableDef {
@@ -244,6 +532,180 @@ func (this *TableDef) GetOrAdvicePrimaryKey() *FieldCollection {
 	return fc
 }
 
+// Make copy of table definition, which could be modified
+// without affecting original one. Check conditions are shared.
+func (this *TableDef) Clone() *TableDef {
+	table := &TableDef{Name: this.Name, PrimaryKeyName: this.PrimaryKeyName}
+	copies := make(map[*FieldDef]*FieldDef)
+	for _, field := range this.Fields.Items {
+		field2 := *field
+		if field.Data != nil {
+			data := *field.Data
+			field2.Data = &data
+		}
+		if field.Default != nil {
+			def := *field.Default
+			field2.Default = &def
+		}
+		copies[field] = &field2
+		table.Fields.Items = append(table.Fields.Items, &field2)
+	}
+	remap := func(fields []*FieldDef) []*FieldDef {
+		var fields2 []*FieldDef
+		for _, field := range fields {
+			if field2, ok := copies[field]; ok {
+				fields2 = append(fields2, field2)
+			} else {
+				fields2 = append(fields2, field)
+			}
+		}
+		return fields2
+	}
+	for _, index := range this.Indexes.Items {
+		index2 := *index
+		index2.Fields = nil
+		for _, item := range index.Fields {
+			item2 := *item
+			item2.Field = remap([]*FieldDef{item.Field})[0]
+			index2.Fields = append(index2.Fields, &item2)
+		}
+		table.Indexes.Items = append(table.Indexes.Items, &index2)
+	}
+	for _, fk := range this.ForeignKeys.Items {
+		fk2 := *fk
+		fk2.Fields = remap(fk.Fields)
+		// self reference
+		if fk.RefTable == this {
+			fk2.RefTable = table
+			fk2.RefFields = remap(fk.RefFields)
+		}
+		table.ForeignKeys.Items = append(table.ForeignKeys.Items, &fk2)
+	}
+	for _, unique := range this.Uniques.Items {
+		unique2 := *unique
+		unique2.Fields = remap(unique.Fields)
+		table.Uniques.Items = append(table.Uniques.Items, &unique2)
+	}
+	for _, check := range this.Checks.Items {
+		check2 := *check
+		table.Checks.Items = append(table.Checks.Items, &check2)
+	}
+	return table
+}
+
+// Sort tables so, that each table goes after tables
+// referenced by its foreign keys. References to tables
+// out of the list and self references are ignored.
+func SortTablesByDependency(tables []*TableDef) ([]*TableDef, error) {
+	var sorted []*TableDef
+	// 0 - not visited, 1 - in progress, 2 - done
+	state := make(map[*TableDef]int)
+	listed := make(map[*TableDef]bool)
+	for _, table := range tables {
+		listed[table] = true
+	}
+	var visit func(table *TableDef, path []string) error
+	visit = func(table *TableDef, path []string) error {
+		path = append(path, table.Name)
+		switch state[table] {
+		case 1:
+			return e("Circular reference found between tables: %v", path)
+		case 2:
+			return nil
+		}
+		state[table] = 1
+		for _, fk := range table.ForeignKeys.Items {
+			ref := fk.RefTable
+			if ref != nil && ref != table && listed[ref] {
+				err := visit(ref, path)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		state[table] = 2
+		sorted = append(sorted, table)
+		return nil
+	}
+	for _, table := range tables {
+		err := visit(table, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// Find foreign key of tables, which refer to the table with "on delete"
+// action modifying referencing rows (cascade, set null or set default),
+// so dropping of the table with foreign keys enforcement on change data
+// of referencing table. Tables matched by name, self references ignored.
+func FindCascadeReference(table *TableDef,
+	tables []*TableDef) (*TableDef, *ForeignKeyDef) {
+	for _, item := range tables {
+		if item.Name == table.Name {
+			continue
+		}
+		for _, fk := range item.ForeignKeys.Items {
+			if fk.RefTable == nil || fk.RefTable.Name != table.Name {
+				continue
+			}
+			switch fk.OnDelete {
+			case FKA_CASCADE, FKA_SET_NULL, FKA_SET_DEFAULT:
+				return item, fk
+			}
+		}
+	}
+	return nil, nil
+}
+
+// View defined by select statement. View is referenced by name
+// in queries, while its columns are taken either from explicit
+// column list or from select statement itself.
+type ViewDef struct {
+	Name    string
+	Columns []string
+	Query   sqlcore.SqlReady
+}
+
+func View(name string, query sqlcore.SqlReady, columns ...string) *ViewDef {
+	v := &ViewDef{Name: name, Query: query, Columns: columns}
+	return v
+}
+
+func (this *ViewDef) GetName() string {
+	return this.Name
+}
+
+func (this *ViewDef) getQuery() (sqlcore.Query, error) {
+	query, ok := this.Query.(sqlcore.Query)
+	if !ok {
+		return nil, e("Can't get columns of view \"%s\", "+
+			"since statement is not a query", this.Name)
+	}
+	return query, nil
+}
+
+func (this *ViewDef) IsTableBased() (bool, sqlcore.Table) {
+	return false, nil
+}
+
+func (this *ViewDef) getColumnList() sqlcore.ColumnList {
+	return sqlcore.ColumnList{Columns: this.Columns, GetQuery: this.getQuery}
+}
+
+func (this *ViewDef) GetColumnCount() (int, error) {
+	return this.getColumnList().GetColumnCount()
+}
+
+func (this *ViewDef) ColumnIsAmbiguous(name string) (bool, error) {
+	return this.getColumnList().ColumnIsAmbiguous(name)
+}
+
+func (this *ViewDef) ColumnExists(name string) (bool, error) {
+	return this.getColumnList().ColumnExists(name)
+}
+
 /*
 type TablesDef struct {
     //    Db    *DatabaseDef
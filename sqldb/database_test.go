@@ -0,0 +1,84 @@
+package sqldb
+
+import (
+	"strings"
+	"testing"
+)
+
+// Create tables with foreign keys, where refs map table
+// name to names of tables it refer to.
+func newTables(names []string, refs map[string][]string) []*TableDef {
+	tables := make(map[string]*TableDef)
+	var list []*TableDef
+	for _, name := range names {
+		t := Table(name)
+		t.Fields.AddAutoinc("id")
+		tables[name] = t
+		list = append(list, t)
+	}
+	for _, name := range names {
+		t := tables[name]
+		for _, ref := range refs[name] {
+			field := t.Fields.AddInt(ref + "_id")
+			t.ForeignKeys.AddForeignKey("", []*FieldDef{field},
+				tables[ref], tables[ref].Fields.Find("id"))
+		}
+	}
+	return list
+}
+
+func TestSortTablesByDependency(t *testing.T) {
+	cases := []struct {
+		name  string
+		names []string
+		refs  map[string][]string
+		want  string
+		err   bool
+	}{
+		{"independent", []string{"a", "b"}, nil, "a b", false},
+		{"chain", []string{"c", "b", "a"},
+			map[string][]string{"c": {"b"}, "b": {"a"}}, "a b c", false},
+		{"diamond", []string{"d", "b", "c", "a"},
+			map[string][]string{"d": {"b", "c"}, "b": {"a"}, "c": {"a"}},
+			"a b c d", false},
+		{"self reference", []string{"a"},
+			map[string][]string{"a": {"a"}}, "a", false},
+		{"circular", []string{"a", "b"},
+			map[string][]string{"a": {"b"}, "b": {"a"}}, "", true},
+		{"circular through third", []string{"a", "b", "c"},
+			map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}}, "", true},
+	}
+	for _, c := range cases {
+		sorted, err := SortTablesByDependency(newTables(c.names, c.refs))
+		if c.err {
+			if err == nil || !strings.Contains(err.Error(), "Circular") {
+				t.Errorf("%s: circular reference error expected, got %v",
+					c.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		var names []string
+		for _, table := range sorted {
+			names = append(names, table.Name)
+		}
+		if got := strings.Join(names, " "); got != c.want {
+			t.Errorf("%s: got %q; want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSortTablesByDependencyOutOfList(t *testing.T) {
+	// reference to table out of the list is ignored
+	tables := newTables([]string{"a", "b"}, map[string][]string{"b": {"a"}})
+	sorted, err := SortTablesByDependency(tables[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sorted) != 1 || sorted[0].Name != "b" {
+		t.Errorf("got %v; want [b]", sorted)
+	}
+}
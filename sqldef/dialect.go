@@ -72,6 +72,22 @@ func (this Dialect) SupportMultipleStatementsInBatch() bool {
 	}
 }
 
+// Maximum number of parameters allowed in single statement;
+// 0 means no limit.
+func (this Dialect) MaxParamCount() int {
+	switch this {
+	case DI_MSTSQL:
+		return 2100
+	case DI_SQLITE:
+		// SQLite 3.32 and later raise limit to 32766
+		return 999
+	case DI_PGSQL, DI_MYSQL:
+		return 65535
+	default:
+		return 0
+	}
+}
+
 func (this Dialect) In(dialects Dialect) bool {
 	return this&dialects != DI_UNDEF
 }
@@ -2,17 +2,20 @@ package sqldelete
 
 import (
 	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
 	"github.com/d2r2/sqlg/sqlexp"
 )
 
 // Generate sql statement according to gereal DELETE syntax:
 //  delete from table1
 //  where conditions
+//  [returning expr1, expr2, ...]
 
 type maker struct {
-	Format  *sqlcore.Format
-	Batch   *sqlcore.StatementBatch
-	Queries *sqlexp.QueryEntries
+	Format    *sqlcore.Format
+	Batch     *sqlcore.StatementBatch
+	Queries   *sqlexp.QueryEntries
+	Returning *returning
 }
 
 func (this *maker) GetExprBuildContext(partKind sqlcore.SqlPartKind,
@@ -29,6 +32,9 @@ func (this *maker) runMaker(direct bool,
 		case sqlcore.SPK_DELETE:
 			sect := part.(*del)
 			this.Queries.AddEntry(sect.DataSource)
+		case sqlcore.SPK_DELETE_RETURNING:
+			sect := part.(*returning)
+			this.Returning = sect
 		}
 	} else {
 		var err error
@@ -39,6 +45,13 @@ func (this *maker) runMaker(direct bool,
 		case sqlcore.SPK_DELETE_WHERE:
 			sect := part.(*where)
 			err = sect.buildWhereSectionSql(this, this.Batch.Last(), stack)
+		case sqlcore.SPK_DELETE_RETURNING:
+			// Microsoft T-SQL "output" section
+			// is generated right after table name
+			if this.Format.Dialect != sqldef.DI_MSTSQL {
+				sect := part.(*returning)
+				err = sect.buildReturningSectionSql(this, this.Batch.Last(), stack)
+			}
 		default:
 			err = e("Unexpected section during generating "+
 				"\"delete\" statement: %v", part)
@@ -60,6 +73,7 @@ func (this *maker) BuildSql(part sqlcore.SqlPart,
 type Delete interface {
 	sqlcore.SqlPart
 	Where(cond sqlexp.Expr) Where
+	Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning
 }
 
 type del struct {
@@ -76,6 +90,11 @@ func (this *del) Where(cond sqlexp.Expr) Where {
 	return uw
 }
 
+// Returning without "where" section affect all rows of the table.
+func (this *del) Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning {
+	return newReturning(this, first, rest...)
+}
+
 func (this *del) buildSql(maker *maker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
 	stat.WriteString("delete from ")
@@ -93,6 +112,13 @@ func (this *del) buildSql(maker *maker,
 		return err
 	}
 	stat.AppendStatPart(stat2)
+	// insert output section if necessary
+	if maker.Returning != nil && maker.Format.Dialect == sqldef.DI_MSTSQL {
+		err = maker.Returning.buildReturningSectionSql(maker, stat, stack)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
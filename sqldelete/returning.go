@@ -0,0 +1,85 @@
+package sqldelete
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+type Returning interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+}
+
+type returning struct {
+	// parent: statement root, "from" or "where" section
+	Parent sqlcore.SqlPart
+	// data
+	Exprs []sqlexp.Expr
+}
+
+func (this *returning) buildReturningSectionSql(maker *maker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	dialect := maker.Format.Dialect
+	switch dialect {
+	case sqldef.DI_PGSQL, sqldef.DI_SQLITE:
+		// SQLite support "returning" starting from version 3.35
+		stat.WriteString(maker.Format.SectionDivider)
+		stat.WriteString("returning ")
+	case sqldef.DI_MSTSQL:
+		stat.WriteString(maker.Format.SectionDivider)
+		stat.WriteString("output ")
+	default:
+		return e("%v dialect doesn't support \"returning\" section "+
+			"in delete statement", dialect)
+	}
+	// returning section can refer to deleted table only
+	r := sqlcore.GetSqlPartRoot(this)
+	root := r.(*del)
+	al := sqlexp.NewQueryEntries()
+	al.AddEntry(root.DataSource)
+	context := sqlexp.NewExprBuildContext(sqlcore.SPK_DELETE_RETURNING,
+		sqlcore.SSPK_EXPR1, stack, maker.Format, al)
+	for i, expr := range this.Exprs {
+		stat2, err := expr.GetSql(context)
+		if err != nil {
+			return err
+		}
+		stat.AppendStatPart(stat2)
+		if i < len(this.Exprs)-1 {
+			stat.WriteString(", ")
+		}
+	}
+	stat.Type = sqlcore.SS_QUERY
+	return nil
+}
+
+func (this *returning) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &maker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, nil
+}
+
+func (this *returning) Validate(format *sqlcore.Format) error {
+	_, err := this.GetSql(format)
+	return err
+}
+
+func (this *returning) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_DELETE_RETURNING
+}
+
+func (this *returning) GetParent() sqlcore.SqlPart {
+	return this.Parent
+}
+
+func newReturning(parent sqlcore.SqlPart, first sqlexp.Expr,
+	rest ...sqlexp.Expr) Returning {
+	exprs := []sqlexp.Expr{first}
+	exprs = append(exprs, rest...)
+	r := &returning{Parent: parent, Exprs: exprs}
+	return r
+}
@@ -8,6 +8,7 @@ import (
 type Where interface {
 	sqlcore.SqlReady
 	sqlcore.SqlPart
+	Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning
 }
 
 type where struct {
@@ -17,6 +18,10 @@ type where struct {
 	Cond sqlexp.Expr
 }
 
+func (this *where) Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning {
+	return newReturning(this, first, rest...)
+}
+
 func (this *where) buildWhereSectionSql(maker *maker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
 	stat.WriteString(maker.Format.SectionDivider)
@@ -0,0 +1,634 @@
+package sqldiff
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/d2r2/sqlg/sqlalter"
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqlcreate"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqldrop"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+// Single modification of database schema.
+type Change struct {
+	Description string
+	Batch       *sqlcore.StatementBatch
+	// change might lead to data loss
+	Destructive bool
+}
+
+type SchemaDiff struct {
+	Changes []*Change
+}
+
+func (this *SchemaDiff) IsEmpty() bool {
+	return len(this.Changes) == 0
+}
+
+func (this *SchemaDiff) GetDestructive() []*Change {
+	var changes []*Change
+	for _, change := range this.Changes {
+		if change.Destructive {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Get statements of all changes in order of execution.
+// Destructive changes are skipped, when withDestructive is false.
+func (this *SchemaDiff) GetBatch(withDestructive bool) *sqlcore.StatementBatch {
+	batch := sqlcore.NewStatementBatch()
+	for _, change := range this.Changes {
+		if change.Destructive && !withDestructive {
+			continue
+		}
+		batch.Items = append(batch.Items, change.Batch.Items...)
+	}
+	return batch
+}
+
+// Changes are collected in groups, which
+// are executed one by one to keep dependencies:
+// foreign keys and other objects are dropped before
+// columns they refer to, and created after them.
+type differ struct {
+	Format *sqlcore.Format
+	// compared sets of tables
+	Current         []*sqldb.TableDef
+	Desired         []*sqldb.TableDef
+	DropForeignKeys []*Change
+	DropObjects     []*Change
+	AlterColumns    []*Change
+	CreateTables    []*Change
+	AddObjects      []*Change
+	AddForeignKeys  []*Change
+	DropColumns     []*Change
+	DropTables      []*Change
+}
+
+// Compare current set of tables (defined in code, or read from database)
+// with desired one and produce changes to migrate from current to desired.
+func Compare(current, desired []*sqldb.TableDef,
+	format *sqlcore.Format) (*SchemaDiff, error) {
+	this := &differ{Format: format, Current: current, Desired: desired}
+	var created []*sqldb.TableDef
+	for _, table := range desired {
+		cur := findTable(current, table.Name)
+		if cur == nil {
+			created = append(created, table)
+			continue
+		}
+		err := this.compareTables(cur, table)
+		if err != nil {
+			return nil, err
+		}
+	}
+	created, err := sqldb.SortTablesByDependency(created)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range created {
+		err = this.addChange(&this.CreateTables, f("create table \"%s\"", table.Name),
+			sqlcreate.NewCreateTable(table), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var dropped []*sqldb.TableDef
+	for _, table := range current {
+		if findTable(desired, table.Name) == nil {
+			dropped = append(dropped, table)
+		}
+	}
+	dropped, err = sqldb.SortTablesByDependency(dropped)
+	if err != nil {
+		return nil, err
+	}
+	// referencing tables dropped first
+	for i := len(dropped) - 1; i >= 0; i-- {
+		table := dropped[i]
+		err = this.addChange(&this.DropTables, f("drop table \"%s\"", table.Name),
+			sqldrop.NewDropTable(table), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	diff := &SchemaDiff{}
+	for _, changes := range [][]*Change{this.DropForeignKeys, this.DropObjects,
+		this.AlterColumns, this.CreateTables, this.AddObjects,
+		this.AddForeignKeys, this.DropColumns, this.DropTables} {
+		diff.Changes = append(diff.Changes, changes...)
+	}
+	return diff, nil
+}
+
+func findTable(tables []*sqldb.TableDef, name string) *sqldb.TableDef {
+	for _, table := range tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+func (this *differ) addChange(changes *[]*Change, description string,
+	statement sqlcore.SqlReady, destructive bool) error {
+	batch, err := statement.GetSql(this.Format)
+	if err != nil {
+		return err
+	}
+	change := &Change{Description: description, Batch: batch,
+		Destructive: destructive}
+	*changes = append(*changes, change)
+	return nil
+}
+
+// Render expressions inline, since objects compared by their sql.
+func (this *differ) getInlineFormat() *sqlcore.Format {
+	format := *this.Format
+	format.AddOptions(sqlcore.BO_INLINE)
+	return &format
+}
+
+func (this *differ) getSqlDataType(field *sqldb.FieldDef) (string, error) {
+	stat := sqlcore.NewStatement(sqlcore.SS_UNDEF)
+	err := sqlcreate.BuildSqlFieldDataType(stat, this.getInlineFormat(),
+		sqlcore.NewCallStack(), field)
+	if err != nil {
+		return "", err
+	}
+	return stat.Sql(), nil
+}
+
+func (this *differ) getSqlDefault(field *sqldb.FieldDef) (string, error) {
+	if field.Default == nil {
+		return "", nil
+	} else if field.Default.Value == nil {
+		return "null", nil
+	}
+	context := sqlexp.NewExprBuildContext(sqlcore.SPK_ALTER_TABLE,
+		sqlcore.SSPK_EXPR1, sqlcore.NewCallStack(), this.getInlineFormat(), nil)
+	stat, err := field.Default.Value.GetSql(context)
+	if err != nil {
+		return "", err
+	}
+	return stat.Sql(), nil
+}
+
+func (this *differ) getSqlConstraint(table *sqldb.TableDef,
+	constraint sqldb.ConstraintDef) (string, error) {
+	stat := sqlcore.NewStatement(sqlcore.SS_UNDEF)
+	err := sqlcreate.BuildSqlConstraint(stat, this.getInlineFormat(),
+		sqlcore.NewCallStack(), table, constraint)
+	if err != nil {
+		return "", err
+	}
+	return stat.Sql(), nil
+}
+
+// Database keep check condition in own form, so quotes of identifiers,
+// table name qualifiers, type casts, parentheses, spaces and letter case
+// are ignored, when check constraints are compared.
+func (this *differ) getSqlConstraintToCompare(table *sqldb.TableDef,
+	constraint sqldb.ConstraintDef) (string, error) {
+	sql, err := this.getSqlConstraint(table, constraint)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := constraint.(*sqldb.CheckDef); !ok {
+		return sql, nil
+	}
+	sql = strings.ToLower(sql)
+	sql = strings.NewReplacer("\"", "", "`", "", "[", "", "]", "").Replace(sql)
+	qualifier := regexp.MustCompile(`\b` +
+		regexp.QuoteMeta(strings.ToLower(table.Name)) + `\.`)
+	sql = qualifier.ReplaceAllString(sql, "")
+	sql = strings.NewReplacer("(", "", ")", "", " ", "",
+		"\t", "", "\n", "").Replace(sql)
+	cast := regexp.MustCompile(`::[a-z_]+`)
+	return cast.ReplaceAllString(sql, ""), nil
+}
+
+func (this *differ) getSqlIndex(table *sqldb.TableDef,
+	index *sqldb.IndexDef) (string, error) {
+	batch, err := sqlcreate.NewCreateIndex(table, index).GetSql(this.getInlineFormat())
+	if err != nil {
+		return "", err
+	}
+	return batch.Items[0].Sql(), nil
+}
+
+// Check that data type change doesn't lead to data loss.
+func (this *differ) isWidening(from, to *sqldef.DataDef) bool {
+	if from.Type == to.Type {
+		return to.Size1 >= from.Size1 && to.Size2 >= from.Size2
+	}
+	families := [][]sqldef.DataType{
+		{sqldef.DT_INT_SMALL, sqldef.DT_INT, sqldef.DT_INT_BIG},
+		{sqldef.DT_REAL, sqldef.DT_DOUBLE},
+		{sqldef.DT_UNICODE_CHAR, sqldef.DT_UNICODE_VARCHAR},
+	}
+	for _, family := range families {
+		fromRank, toRank := -1, -1
+		for i, item := range family {
+			if item == from.Type {
+				fromRank = i
+			}
+			if item == to.Type {
+				toRank = i
+			}
+		}
+		if fromRank != -1 && toRank != -1 {
+			return fromRank < toRank && to.Size1 >= from.Size1
+		}
+	}
+	return false
+}
+
+func (this *differ) getConstraints(table *sqldb.TableDef) []sqldb.ConstraintDef {
+	var constraints []sqldb.ConstraintDef
+	for _, unique := range table.Uniques.Items {
+		constraints = append(constraints, unique)
+	}
+	for _, check := range table.Checks.Items {
+		constraints = append(constraints, check)
+	}
+	return constraints
+}
+
+func (this *differ) getForeignKeys(table *sqldb.TableDef) []sqldb.ConstraintDef {
+	var constraints []sqldb.ConstraintDef
+	for _, fk := range table.ForeignKeys.Items {
+		constraints = append(constraints, fk)
+	}
+	return constraints
+}
+
+// Find constraints, which should be dropped from current
+// table and added to desired one, comparing their sql.
+func (this *differ) diffConstraints(cur, des *sqldb.TableDef,
+	curItems, desItems []sqldb.ConstraintDef) (dropped,
+	added []sqldb.ConstraintDef, err error) {
+	curSql := make(map[string]string)
+	for _, item := range curItems {
+		curSql[item.GetOrAdviceName(cur)], err = this.getSqlConstraintToCompare(cur, item)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	desSql := make(map[string]string)
+	for _, item := range desItems {
+		name := item.GetOrAdviceName(des)
+		desSql[name], err = this.getSqlConstraintToCompare(des, item)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sql, ok := curSql[name]; !ok || sql != desSql[name] {
+			added = append(added, item)
+		}
+	}
+	for _, item := range curItems {
+		name := item.GetOrAdviceName(cur)
+		if sql, ok := desSql[name]; !ok || sql != curSql[name] {
+			dropped = append(dropped, item)
+		}
+	}
+	return dropped, added, nil
+}
+
+func (this *differ) diffIndexes(cur, des *sqldb.TableDef) (dropped,
+	added []*sqldb.IndexDef, err error) {
+	curSql := make(map[string]string)
+	for _, index := range cur.Indexes.Items {
+		curSql[index.GetOrAdviceName(cur)], err = this.getSqlIndex(cur, index)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	desSql := make(map[string]string)
+	for _, index := range des.Indexes.Items {
+		name := index.GetOrAdviceName(des)
+		desSql[name], err = this.getSqlIndex(des, index)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sql, ok := curSql[name]; !ok || sql != desSql[name] {
+			added = append(added, index)
+		}
+	}
+	for _, index := range cur.Indexes.Items {
+		name := index.GetOrAdviceName(cur)
+		if sql, ok := desSql[name]; !ok || sql != curSql[name] {
+			dropped = append(dropped, index)
+		}
+	}
+	return dropped, added, nil
+}
+
+// Check that index is unique one with the same name
+// and fields as unique constraint.
+func (this *differ) isUniqueIndexOf(indexTable *sqldb.TableDef,
+	index *sqldb.IndexDef, uniqueTable *sqldb.TableDef,
+	unique *sqldb.UniqueDef) bool {
+	if !index.IsUnique || index.Filter != nil ||
+		index.GetOrAdviceName(indexTable) != unique.GetOrAdviceName(uniqueTable) ||
+		len(index.Fields) != len(unique.Fields) {
+		return false
+	}
+	for i, item := range index.Fields {
+		if item.Descending || item.Field.Name != unique.Fields[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// MySQL implement unique constraint as unique index and doesn't
+// distinguish them in catalog, so unique index and unique constraint
+// with the same name and fields are considered equal and excluded
+// from lists of changes.
+func (this *differ) matchUniqueIndexes(indexTable *sqldb.TableDef,
+	indexes []*sqldb.IndexDef, uniqueTable *sqldb.TableDef,
+	constraints []sqldb.ConstraintDef) ([]*sqldb.IndexDef, []sqldb.ConstraintDef) {
+	var unmatched []*sqldb.IndexDef
+	for _, index := range indexes {
+		matched := false
+		for i, constraint := range constraints {
+			unique, ok := constraint.(*sqldb.UniqueDef)
+			if ok && this.isUniqueIndexOf(indexTable, index, uniqueTable, unique) {
+				constraints = append(constraints[:i:i], constraints[i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, index)
+		}
+	}
+	return unmatched, constraints
+}
+
+func (this *differ) getPrimaryKeyNames(table *sqldb.TableDef) string {
+	var names string
+	for _, field := range table.GetOrAdvicePrimaryKey().Items {
+		names += f("%s;", field.Name)
+	}
+	return names
+}
+
+// Modification of existing column.
+type columnDiff struct {
+	Current     *sqldb.FieldDef
+	Desired     *sqldb.FieldDef
+	Type        bool
+	Nullability bool
+	Default     bool
+}
+
+func (this *differ) diffColumns(cur, des *sqldb.TableDef) (dropped []*sqldb.FieldDef,
+	added []*sqldb.FieldDef, altered []*columnDiff, err error) {
+	for _, field := range des.Fields.Items {
+		curField := cur.Fields.Find(field.Name)
+		if curField == nil {
+			added = append(added, field)
+			continue
+		}
+		item := &columnDiff{Current: curField, Desired: field}
+		curType, err := this.getSqlDataType(curField)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		desType, err := this.getSqlDataType(field)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		item.Type = curType != desType
+		item.Nullability = curField.IsNullable != field.IsNullable
+		curDefault, err := this.getSqlDefault(curField)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		desDefault, err := this.getSqlDefault(field)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		item.Default = curDefault != desDefault
+		if item.Type || item.Nullability || item.Default {
+			altered = append(altered, item)
+		}
+	}
+	for _, field := range cur.Fields.Items {
+		if des.Fields.Find(field.Name) == nil {
+			dropped = append(dropped, field)
+		}
+	}
+	return dropped, added, altered, nil
+}
+
+func (this *differ) compareTables(cur, des *sqldb.TableDef) error {
+	droppedFks, addedFks, err := this.diffConstraints(cur, des,
+		this.getForeignKeys(cur), this.getForeignKeys(des))
+	if err != nil {
+		return err
+	}
+	droppedCons, addedCons, err := this.diffConstraints(cur, des,
+		this.getConstraints(cur), this.getConstraints(des))
+	if err != nil {
+		return err
+	}
+	droppedIndexes, addedIndexes, err := this.diffIndexes(cur, des)
+	if err != nil {
+		return err
+	}
+	if this.Format.Dialect == sqldef.DI_MYSQL {
+		droppedIndexes, addedCons = this.matchUniqueIndexes(cur,
+			droppedIndexes, des, addedCons)
+		addedIndexes, droppedCons = this.matchUniqueIndexes(des,
+			addedIndexes, cur, droppedCons)
+	}
+	droppedColumns, addedColumns, alteredColumns, err := this.diffColumns(cur, des)
+	if err != nil {
+		return err
+	}
+	pkChanged := this.getPrimaryKeyNames(cur) != this.getPrimaryKeyNames(des)
+	if this.Format.Dialect == sqldef.DI_SQLITE {
+		// SQLite can add columns in place only,
+		// so rest of modifications require table to be rebuilt
+		recreate := len(droppedFks) > 0 || len(addedFks) > 0 ||
+			len(droppedCons) > 0 || len(addedCons) > 0 ||
+			len(droppedColumns) > 0 || len(alteredColumns) > 0 || pkChanged
+		for _, field := range addedColumns {
+			if field.GetOrAdviceIsPrimaryKey() || !field.IsNullable &&
+				(field.Default == nil || field.Default.Value == nil) {
+				recreate = true
+			}
+		}
+		if recreate {
+			return this.recreateTable(cur, des, addedColumns,
+				droppedColumns, alteredColumns)
+		}
+	}
+	table := cur.Name
+	for _, fk := range droppedFks {
+		name := fk.GetOrAdviceName(cur)
+		err = this.addChange(&this.DropForeignKeys,
+			f("drop foreign key \"%s\" of table \"%s\"", name, table),
+			sqlalter.NewDropConstraint(cur, name), false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, constraint := range droppedCons {
+		name := constraint.GetOrAdviceName(cur)
+		err = this.addChange(&this.DropObjects,
+			f("drop constraint \"%s\" of table \"%s\"", name, table),
+			sqlalter.NewDropConstraint(cur, name), false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, index := range droppedIndexes {
+		name := index.GetOrAdviceName(cur)
+		err = this.addChange(&this.DropObjects,
+			f("drop index \"%s\" of table \"%s\"", name, table),
+			sqldrop.NewDropIndex(cur, name), false)
+		if err != nil {
+			return err
+		}
+	}
+	// columns altered step by step, so each
+	// statement built from previous table state
+	working := cur.Clone()
+	for _, field := range addedColumns {
+		err = this.addChange(&this.AlterColumns,
+			f("add column \"%s\" to table \"%s\"", field.Name, table),
+			sqlalter.NewAddColumn(working, field), false)
+		if err != nil {
+			return err
+		}
+		working.Fields.Items = append(working.Fields.Items, field)
+	}
+	for _, item := range alteredColumns {
+		name := item.Desired.Name
+		field := working.Fields.Find(name)
+		if item.Type {
+			err = this.addChange(&this.AlterColumns,
+				f("alter type of column \"%s\" of table \"%s\"", name, table),
+				sqlalter.NewAlterColumnType(working, name, item.Desired.Data),
+				!this.isWidening(item.Current.Data, item.Desired.Data))
+			if err != nil {
+				return err
+			}
+			field.Data = item.Desired.Data
+		}
+		if item.Nullability {
+			err = this.addChange(&this.AlterColumns,
+				f("alter nullability of column \"%s\" of table \"%s\"", name, table),
+				sqlalter.NewAlterColumnNullability(working, name,
+					item.Desired.IsNullable), false)
+			if err != nil {
+				return err
+			}
+			field.IsNullable = item.Desired.IsNullable
+		}
+		if item.Default {
+			var alter sqlalter.AlterTable
+			if item.Desired.Default == nil {
+				alter = sqlalter.NewDropColumnDefault(working, name)
+			} else {
+				alter = sqlalter.NewAlterColumnDefault(working, name,
+					item.Desired.Default.Value)
+			}
+			err = this.addChange(&this.AlterColumns,
+				f("alter default of column \"%s\" of table \"%s\"", name, table),
+				alter, false)
+			if err != nil {
+				return err
+			}
+			field.Default = item.Desired.Default
+		}
+	}
+	if pkChanged {
+		err = this.addChange(&this.AlterColumns,
+			f("alter primary key of table \"%s\"", table),
+			sqlalter.NewAlterPrimaryKey(working,
+				des.GetOrAdvicePrimaryKey().Items...), false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, constraint := range addedCons {
+		err = this.addChange(&this.AddObjects,
+			f("add constraint \"%s\" to table \"%s\"",
+				constraint.GetOrAdviceName(des), table),
+			sqlalter.NewAddConstraint(des, constraint), false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, index := range addedIndexes {
+		err = this.addChange(&this.AddObjects,
+			f("create index \"%s\" of table \"%s\"",
+				index.GetOrAdviceName(des), table),
+			sqlcreate.NewCreateIndex(des, index), false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, fk := range addedFks {
+		err = this.addChange(&this.AddForeignKeys,
+			f("add foreign key \"%s\" to table \"%s\"",
+				fk.GetOrAdviceName(des), table),
+			sqlalter.NewAddConstraint(des, fk), false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, field := range droppedColumns {
+		err = this.addChange(&this.DropColumns,
+			f("drop column \"%s\" of table \"%s\"", field.Name, table),
+			sqlalter.NewDropColumn(working, field.Name), true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *differ) recreateTable(cur, des *sqldb.TableDef,
+	addedColumns, droppedColumns []*sqldb.FieldDef,
+	alteredColumns []*columnDiff) error {
+	destructive := len(droppedColumns) > 0
+	for _, item := range alteredColumns {
+		if item.Type && !this.isWidening(item.Current.Data, item.Desired.Data) {
+			destructive = true
+		}
+	}
+	// existing rows can't be copied to the table with
+	// "not null" column without default value, except
+	// autoincrement one filled automatically
+	for _, field := range addedColumns {
+		autoinc := field.Data.Type.In(sqldef.DT_AUTOINC_INT | sqldef.DT_AUTOINC_INT_BIG)
+		if !field.IsNullable && !autoinc &&
+			(field.Default == nil || field.Default.Value == nil) {
+			destructive = true
+		}
+	}
+	// table is dropped during recreate, which fire "on delete"
+	// actions of referencing foreign keys, unless foreign keys
+	// enforcement is disabled outside of transaction
+	for _, tables := range [][]*sqldb.TableDef{this.Current, this.Desired} {
+		ref, _ := sqldb.FindCascadeReference(cur, tables)
+		if ref != nil {
+			destructive = true
+		}
+	}
+	return this.addChange(&this.AlterColumns,
+		f("recreate table \"%s\"", cur.Name),
+		sqlalter.NewRecreateTable(cur, des), destructive)
+}
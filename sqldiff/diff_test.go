@@ -0,0 +1,153 @@
+package sqldiff
+
+import (
+	"testing"
+
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+// Create table "dept" and, if action specified, table "emp",
+// which refer to "dept" by foreign key with the action.
+func newTables(action *sqldb.ForeignKeyAction) []*sqldb.TableDef {
+	dept := sqldb.Table("dept")
+	dept.Fields.AddAutoinc("id")
+	dept.Fields.AddUnicodeVariable("name", 50)
+	tables := []*sqldb.TableDef{dept}
+	if action != nil {
+		emp := sqldb.Table("emp")
+		emp.Fields.AddAutoinc("id")
+		field := emp.Fields.AddInt("dept_id")
+		emp.ForeignKeys.AddForeignKey("", []*sqldb.FieldDef{field},
+			dept).OnDeleteDo(*action)
+		tables = append(tables, emp)
+	}
+	return tables
+}
+
+func TestCompareRecreateTableSqlite(t *testing.T) {
+	noAction, cascade := sqldb.FKA_NO_ACTION, sqldb.FKA_CASCADE
+	cases := []struct {
+		name        string
+		action      *sqldb.ForeignKeyAction
+		modify      func(dept *sqldb.TableDef)
+		recreate    bool
+		destructive bool
+	}{
+		{"add nullable column", nil, func(dept *sqldb.TableDef) {
+			dept.Fields.AddInt("code")
+		}, false, false},
+		{"add not null column with default", nil, func(dept *sqldb.TableDef) {
+			dept.Fields.AddInt("code").NotNull().DefaultValue(0)
+		}, false, false},
+		{"widen column", nil, func(dept *sqldb.TableDef) {
+			dept.Fields.Find("name").Data.Size1 = 100
+		}, true, false},
+		{"narrow column", nil, func(dept *sqldb.TableDef) {
+			dept.Fields.Find("name").Data.Size1 = 10
+		}, true, true},
+		{"add not null column", nil, func(dept *sqldb.TableDef) {
+			dept.Fields.AddInt("code").NotNull()
+			dept.Fields.Find("name").Data.Size1 = 100
+		}, true, true},
+		{"widen referenced column", &noAction, func(dept *sqldb.TableDef) {
+			dept.Fields.Find("name").Data.Size1 = 100
+		}, true, false},
+		{"widen column with cascade reference", &cascade, func(dept *sqldb.TableDef) {
+			dept.Fields.Find("name").Data.Size1 = 100
+		}, true, true},
+	}
+	for _, c := range cases {
+		current := newTables(c.action)
+		desired := newTables(c.action)
+		c.modify(desired[0])
+		diff, err := Compare(current, desired, sqlcore.NewFormat(sqldef.DI_SQLITE))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		if len(diff.Changes) != 1 {
+			t.Errorf("%s: expected single change, got %d", c.name, len(diff.Changes))
+			continue
+		}
+		change := diff.Changes[0]
+		recreate := change.Description == "recreate table \"dept\""
+		if recreate != c.recreate {
+			t.Errorf("%s: unexpected change: %s", c.name, change.Description)
+		}
+		if change.Destructive != c.destructive {
+			t.Errorf("%s: expected destructive %v, got %v",
+				c.name, c.destructive, change.Destructive)
+		}
+	}
+}
+
+func TestCompareCheckAsReadFromCatalog(t *testing.T) {
+	ef := sqlexp.Factory()
+	cases := []struct {
+		dialect sqldef.Dialect
+		cond    string
+		changes int
+	}{
+		{sqldef.DI_PGSQL, "(salary > 0)", 0},
+		{sqldef.DI_PGSQL, "(salary > 1)", 2},
+		{sqldef.DI_MYSQL, "(`salary` > 0)", 0},
+		{sqldef.DI_SQLITE, "salary > 0", 0},
+		{sqldef.DI_MSTSQL, "([salary]>(0))", 0},
+		{sqldef.DI_MSTSQL, "([salary]>=(0))", 2},
+	}
+	for _, c := range cases {
+		current := newTables(nil)
+		raw := ef.FuncDef(ef.FuncDialectDef(c.dialect, c.cond, 0, 0))
+		current[0].Fields.AddInt("salary")
+		// catalog keep advised name of desired check
+		current[0].Checks.AddCheck("CK_dept_salary", ef.Func(raw))
+		desired := newTables(nil)
+		dept := desired[0]
+		dept.Fields.AddInt("salary")
+		dept.Checks.AddCheck("", ef.Greater(ef.Field(dept, "salary"), 0))
+		diff, err := Compare(current, desired, sqlcore.NewFormat(c.dialect))
+		if err != nil {
+			t.Errorf("%v %q: unexpected error: %v", c.dialect, c.cond, err)
+			continue
+		}
+		if len(diff.Changes) != c.changes {
+			t.Errorf("%v %q: expected %d changes, got %d",
+				c.dialect, c.cond, c.changes, len(diff.Changes))
+		}
+	}
+}
+
+func TestCompareUniqueIndexAndConstraint(t *testing.T) {
+	cases := []struct {
+		dialect sqldef.Dialect
+		index   string
+		changes int
+	}{
+		{sqldef.DI_MYSQL, "UQ_dept_name", 0},
+		{sqldef.DI_MYSQL, "UX_dept_name", 2},
+		{sqldef.DI_PGSQL, "UQ_dept_name", 2},
+	}
+	for _, c := range cases {
+		// MySQL read unique constraint as unique index
+		current := newTables(nil)
+		current[0].Indexes.AddUniqueIndex(c.index,
+			current[0].Fields.Find("name"))
+		desired := newTables(nil)
+		desired[0].Uniques.AddUnique("", desired[0].Fields.Find("name"))
+		for _, pair := range [][2][]*sqldb.TableDef{
+			{current, desired}, {desired, current}} {
+			diff, err := Compare(pair[0], pair[1], sqlcore.NewFormat(c.dialect))
+			if err != nil {
+				t.Errorf("%v %s: unexpected error: %v", c.dialect, c.index, err)
+				continue
+			}
+			if len(diff.Changes) != c.changes {
+				t.Errorf("%v %s: expected %d changes, got %d",
+					c.dialect, c.index, c.changes, len(diff.Changes))
+			}
+		}
+	}
+}
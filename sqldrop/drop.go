@@ -30,6 +30,18 @@ func ifExistsNotExistsBlockMicrosoftCase(part sqlcore.SqlPart,
 			sqldef.DI_MSTSQL, "object_id({})", 1, 2))
 		name := format.FormatTableName(sect.Table.Name)
 		fnc = ef.IsNotNull(ef.Func(objectId, name, "U"))
+	case sqlcore.SPK_DROP_INDEX:
+		sect := part.(*dropIndex)
+		indexId := ef.FuncDef(ef.FuncDialectDef(sqldef.DI_MSTSQL,
+			"indexproperty(object_id({0}), {1}, 'IndexID')", 2, 2))
+		name := format.FormatTableName(sect.Table.Name)
+		fnc = ef.IsNotNull(ef.Func(indexId, name, sect.Name))
+	case sqlcore.SPK_DROP_VIEW:
+		sect := part.(*dropView)
+		objectId := ef.FuncDef(ef.FuncDialectDef(
+			sqldef.DI_MSTSQL, "object_id({})", 1, 2))
+		name := format.FormatObjectName(sect.View.Name)
+		fnc = ef.IsNotNull(ef.Func(objectId, name, "V"))
 	}
 	context := sqlexp.NewExprBuildContext(partKind, sqlcore.SSPK_EXPR1,
 		stack, format, nil)
@@ -0,0 +1,114 @@
+package sqldrop
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+)
+
+type dropIndexMaker struct {
+	Format *sqlcore.Format
+	Batch  *sqlcore.StatementBatch
+}
+
+func (this *dropIndexMaker) buildDropIndex(sect *dropIndex,
+	stack *sqlcore.CallStack) error {
+	if this.Format.DoIfObjectExistsNotExists() &&
+		this.Format.Dialect == sqldef.DI_MSTSQL {
+		this.Format.IncIndentLevel()
+		defer this.Format.DecIndentLevel()
+	}
+	err := sect.buildDropIndexSql(this, this.Batch.Last(), stack)
+	return err
+}
+
+func (this *dropIndexMaker) runMaker(direct bool,
+	part sqlcore.SqlPart, stack *sqlcore.CallStack) error {
+	if direct == false {
+		switch part.GetPartKind() {
+		case sqlcore.SPK_DROP_INDEX:
+			sect := part.(*dropIndex)
+			err := this.buildDropIndex(sect, stack)
+			if err != nil {
+				return err
+			}
+			if this.Format.DoIfObjectExistsNotExists() &&
+				this.Format.Dialect == sqldef.DI_MSTSQL {
+				stat := this.Batch.Last()
+				newstat, err := ifExistsNotExistsBlockMicrosoftCase(
+					part, stat, this.Format, stack)
+				if err != nil {
+					return err
+				}
+				this.Batch.Replace(stat, newstat)
+			}
+			return nil
+		default:
+			return e("Unexpected section during generating "+
+				"\"drop index\" statement: %v", part)
+		}
+	}
+	return nil
+}
+
+func (this *dropIndexMaker) BuildSql(part sqlcore.SqlPart,
+	format *sqlcore.Format) error {
+	f := *format
+	this.Format = &f
+	this.Batch = sqlcore.NewStatementBatch()
+	this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
+	return sqlcore.IterateSqlParents(false, part, this.runMaker)
+}
+
+type DropIndex interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+}
+
+type dropIndex struct {
+	Table *sqldb.TableDef
+	Name  string
+}
+
+func NewDropIndex(table *sqldb.TableDef, name string) DropIndex {
+	r := &dropIndex{Table: table, Name: name}
+	return r
+}
+
+func (this *dropIndex) buildDropIndexSql(maker *dropIndexMaker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	stat.WriteString(maker.Format.GetLeadingSpace())
+	stat.WriteString("drop index ")
+	if maker.Format.DoIfObjectExistsNotExists() {
+		switch maker.Format.Dialect {
+		case sqldef.DI_PGSQL, sqldef.DI_SQLITE:
+			stat.WriteString("if exists ")
+		case sqldef.DI_MYSQL:
+			log.Warnf("%v dialect doesn't support \"IF EXISTS\" option "+
+				"for \"drop index\" statement", maker.Format.Dialect)
+		}
+	}
+	stat.WriteString(maker.Format.FormatObjectName(this.Name))
+	// index names are unique within table only
+	if maker.Format.Dialect.In(sqldef.DI_MYSQL | sqldef.DI_MSTSQL) {
+		stat.WriteString(" on %s", maker.Format.FormatTableName(this.Table.Name))
+	}
+	return nil
+}
+
+func (this *dropIndex) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &dropIndexMaker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, err
+}
+
+func (this *dropIndex) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_DROP_INDEX
+}
+
+func (this *dropIndex) GetParent() sqlcore.SqlPart {
+	return nil
+}
@@ -0,0 +1,103 @@
+package sqldrop
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+)
+
+type dropViewMaker struct {
+	Format *sqlcore.Format
+	Batch  *sqlcore.StatementBatch
+}
+
+func (this *dropViewMaker) buildDropView(sect *dropView,
+	stack *sqlcore.CallStack) error {
+	if this.Format.DoIfObjectExistsNotExists() &&
+		this.Format.Dialect == sqldef.DI_MSTSQL {
+		this.Format.IncIndentLevel()
+		defer this.Format.DecIndentLevel()
+	}
+	err := sect.buildDropViewSql(this, this.Batch.Last(), stack)
+	return err
+}
+
+func (this *dropViewMaker) runMaker(direct bool,
+	part sqlcore.SqlPart, stack *sqlcore.CallStack) error {
+	if direct == false {
+		switch part.GetPartKind() {
+		case sqlcore.SPK_DROP_VIEW:
+			sect := part.(*dropView)
+			err := this.buildDropView(sect, stack)
+			if err != nil {
+				return err
+			}
+			if this.Format.DoIfObjectExistsNotExists() &&
+				this.Format.Dialect == sqldef.DI_MSTSQL {
+				stat := this.Batch.Last()
+				newstat, err := ifExistsNotExistsBlockMicrosoftCase(
+					part, stat, this.Format, stack)
+				if err != nil {
+					return err
+				}
+				this.Batch.Replace(stat, newstat)
+			}
+			return nil
+		default:
+			return e("Unexpected section during generating "+
+				"\"drop view\" statement: %v", part)
+		}
+	}
+	return nil
+}
+
+func (this *dropViewMaker) BuildSql(part sqlcore.SqlPart,
+	format *sqlcore.Format) error {
+	f := *format
+	this.Format = &f
+	this.Batch = sqlcore.NewStatementBatch()
+	this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
+	return sqlcore.IterateSqlParents(false, part, this.runMaker)
+}
+
+type DropView interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+}
+
+type dropView struct {
+	View *sqldb.ViewDef
+}
+
+func NewDropView(view *sqldb.ViewDef) DropView {
+	r := &dropView{View: view}
+	return r
+}
+
+func (this *dropView) buildDropViewSql(maker *dropViewMaker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	stat.WriteString(maker.Format.GetLeadingSpace())
+	stat.WriteString("drop view ")
+	if maker.Format.DoIfObjectExistsNotExists() &&
+		maker.Format.Dialect != sqldef.DI_MSTSQL {
+		stat.WriteString("if exists ")
+	}
+	stat.WriteString(maker.Format.FormatObjectName(this.View.Name))
+	return nil
+}
+func (this *dropView) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &dropViewMaker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, err
+}
+
+func (this *dropView) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_DROP_VIEW
+}
+
+func (this *dropView) GetParent() sqlcore.SqlPart {
+	return nil
+}
@@ -39,6 +39,8 @@ const (
 	SF_LIKE        //  expr1 like expr2
 	SF_IN          //  expr0 in (expr1, expr2, ... exprN)
 	SF_NOT_IN      //  expr0 not in (expr1, expr2, ... exprN)
+	SF_EXISTS      //  exists (subquery)
+	SF_NOT_EXISTS  //  not exists (subquery)
 	SF_BEETWEN     //  expr0 between (expr1, expr2)
 	SF_AND         //  expr1 and expr2
 	SF_OR          //  expr1 or expr2
@@ -61,6 +63,16 @@ const (
 	// group by asc, desc hints
 	SF_ORD_ASC  //  field1 asc
 	SF_ORD_DESC //  field1 desc
+	// window functions, used only with "over" clause
+	SF_WIN_ROW_NUMBER   //  row_number()
+	SF_WIN_RANK         //  rank()
+	SF_WIN_DENSE_RANK   //  dense_rank()
+	SF_WIN_LAG          //  lag(op1, offset, default)
+	SF_WIN_LEAD         //  lead(op1, offset, default)
+	SF_WIN_FIRST_VALUE  //  first_value(op1)
+	SF_WIN_FRAME_ROWS   //  rows between bound1 and bound2
+	SF_WIN_FRAME_RANGE  //  range between bound1 and bound2
+	SF_WIN_FRAME_GROUPS //  groups between bound1 and bound2
 	// sql specific: date functions
 	SF_CURDATE
 	SF_CURTIME
@@ -73,19 +85,21 @@ const (
 
 func (sf SqlFunc) String() string {
 	fnc := map[SqlFunc]string{
-		SF_UNDEF:    "undefined function",
-		SF_EQUAL:    "op1 = op2",
-		SF_NOT_EQ:   "op1 <> op2",
-		SF_LESS:     "op1 < op2",
-		SF_LESS_EQ:  "op1 <= op2",
-		SF_GREAT:    "op1 > op2",
-		SF_GREAT_EQ: "op1 >= op2",
-		SF_LIKE:     "op1 like op2",
-		SF_IN:       "op in (op1, op2, ... opn)",
-		SF_NOT_IN:   "not in (op1, op2, ... opn)",
-		SF_BEETWEN:  "op1 between (op1, op2)",
-		SF_AND:      "op1 and op2",
-		SF_OR:       "op1 or op2",
+		SF_UNDEF:      "undefined function",
+		SF_EQUAL:      "op1 = op2",
+		SF_NOT_EQ:     "op1 <> op2",
+		SF_LESS:       "op1 < op2",
+		SF_LESS_EQ:    "op1 <= op2",
+		SF_GREAT:      "op1 > op2",
+		SF_GREAT_EQ:   "op1 >= op2",
+		SF_LIKE:       "op1 like op2",
+		SF_IN:         "op in (op1, op2, ... opn)",
+		SF_NOT_IN:     "not in (op1, op2, ... opn)",
+		SF_EXISTS:     "exists (subquery)",
+		SF_NOT_EXISTS: "not exists (subquery)",
+		SF_BEETWEN:    "op1 between (op1, op2)",
+		SF_AND:        "op1 and op2",
+		SF_OR:         "op1 or op2",
 		// aggregate functions
 		SF_AGR_SUM:   "sum(op1)",
 		SF_AGR_MIN:   "min(op1)",
@@ -95,6 +109,16 @@ func (sf SqlFunc) String() string {
 		// group by asc, desc hints
 		SF_ORD_ASC:  "expr1 asc",
 		SF_ORD_DESC: "expr1 desc",
+		// window functions
+		SF_WIN_ROW_NUMBER:   "row_number()",
+		SF_WIN_RANK:         "rank()",
+		SF_WIN_DENSE_RANK:   "dense_rank()",
+		SF_WIN_LAG:          "lag(op1)",
+		SF_WIN_LEAD:         "lead(op1)",
+		SF_WIN_FIRST_VALUE:  "first_value(op1)",
+		SF_WIN_FRAME_ROWS:   "rows between op1 and op2",
+		SF_WIN_FRAME_RANGE:  "range between op1 and op2",
+		SF_WIN_FRAME_GROUPS: "groups between op1 and op2",
 		// sql specific: date functions
 		SF_CURDATE:     "get_current_date()",
 		SF_CURTIME:     "get_current_time()",
@@ -124,17 +148,52 @@ func (this *QueryEntries) AddEntry(query sqlcore.Query) {
 	this.Queries = append(this.Queries, query)
 }
 
-func (this *QueryEntries) FindEntry(query sqlcore.Query) (sqlcore.Query, bool) {
+// Find entry, which is query itself.
+func (this *QueryEntries) findEntryByIdentity(query sqlcore.Query) (sqlcore.Query, bool) {
+	var entryFound sqlcore.Query
+	ambiguous := false
+	for _, entry := range this.Queries {
+		if entry == query {
+			if entryFound == nil {
+				entryFound = entry
+			} else {
+				ambiguous = true
+				break
+			}
+		}
+	}
+	return entryFound, ambiguous
+}
+
+// Find entry, which is alias of query.
+func (this *QueryEntries) findEntryByAliasSource(query sqlcore.Query) (sqlcore.Query, bool) {
 	var entryFound sqlcore.Query
 	ambiguous := false
 	for _, entry := range this.Queries {
-		queryTableBased, queryTable := query.IsTableBased()
-		entryTableBased, entryTable := entry.IsTableBased()
 		entryAlias, entryAliasBased := entry.(sqlcore.QueryAlias)
-		if queryTableBased && entryTableBased &&
-			queryTable.GetName() == entryTable.GetName() ||
-			entryAliasBased && entryAlias.GetSource() == query ||
-			entry == query {
+		if entryAliasBased && entryAlias.GetSource() == query {
+			if entryFound == nil {
+				entryFound = entry
+			} else {
+				ambiguous = true
+				break
+			}
+		}
+	}
+	return entryFound, ambiguous
+}
+
+// Find entry, which is based on the table with the same name.
+func (this *QueryEntries) findEntryByTableName(query sqlcore.Query) (sqlcore.Query, bool) {
+	var entryFound sqlcore.Query
+	ambiguous := false
+	queryTableBased, queryTable := query.IsTableBased()
+	if !queryTableBased {
+		return nil, false
+	}
+	for _, entry := range this.Queries {
+		entryTableBased, entryTable := entry.IsTableBased()
+		if entryTableBased && queryTable.GetName() == entryTable.GetName() {
 			if entryFound == nil {
 				entryFound = entry
 			} else {
@@ -146,6 +205,20 @@ func (this *QueryEntries) FindEntry(query sqlcore.Query) (sqlcore.Query, bool) {
 	return entryFound, ambiguous
 }
 
+// FindEntry look for entry, which is query itself or alias of query;
+// if there is no such entry, then entry based on table with the same
+// name is looked for.
+func (this *QueryEntries) FindEntry(query sqlcore.Query) (sqlcore.Query, bool) {
+	entry, ambiguous := this.findEntryByIdentity(query)
+	if entry == nil {
+		entry, ambiguous = this.findEntryByAliasSource(query)
+	}
+	if entry == nil {
+		entry, ambiguous = this.findEntryByTableName(query)
+	}
+	return entry, ambiguous
+}
+
 type ExprBuildContext struct {
 	SqlPartKind    sqlcore.SqlPartKind
 	SqlSubPartKind sqlcore.SqlSubPartKind
@@ -173,7 +246,10 @@ func FormatPrettyDataSource(query sqlcore.Query,
 	var str string
 	tableBased, table := query.IsTableBased()
 	sqlValid, sqlValidOk := query.(sqlcore.SqlReady)
-	if tableBased {
+	queryNamed, namedBased := query.(sqlcore.QueryNamed)
+	if namedBased {
+		str = f("query \"%s\"", queryNamed.GetName())
+	} else if tableBased {
 		var buf bytes.Buffer
 		for i, field := range table.GetFields() {
 			buf.WriteString(f("\"%s\"", field.GetName()))
@@ -214,7 +290,44 @@ func (this *TokenField) FindEntryAndValidate(context *ExprBuildContext) (sqlcore
 	if err != nil {
 		return nil, err
 	}
-	entry, entryAmb := context.DataSources.FindEntry(this.DataSource)
+	// current statement scope go first, followed by outer
+	// statements scopes for correlated references
+	scopes := []*QueryEntries{context.DataSources}
+	for _, scope := range context.Format.OuterDataSources {
+		scopes = append(scopes, &QueryEntries{Queries: scope})
+	}
+	// query itself has priority over its alias, and alias
+	// has priority over table name match in any scope, otherwise
+	// reference to outer table would be bound to inner alias
+	// of the same table in correlated subquery
+	var entry sqlcore.Query
+	var entryAmb bool
+	for _, find := range []func(*QueryEntries, sqlcore.Query) (sqlcore.Query, bool){
+		(*QueryEntries).findEntryByIdentity,
+		(*QueryEntries).findEntryByAliasSource,
+	} {
+		for _, scope := range scopes {
+			entry, entryAmb = find(scope, this.DataSource)
+			if entry != nil {
+				break
+			}
+		}
+		if entry != nil {
+			break
+		}
+	}
+	if entry == nil {
+		for _, scope := range scopes {
+			entry2, entryAmb2 := scope.findEntryByTableName(this.DataSource)
+			if entry2 != nil {
+				if entry != nil {
+					entryAmb = true
+					break
+				}
+				entry, entryAmb = entry2, entryAmb2
+			}
+		}
+	}
 	if entry == nil {
 		str := f("Column \"%s\" is associated with %s, "+
 			"which haven't been added to the statement", this.Name, objstr)
@@ -244,7 +357,14 @@ func (this *TokenField) FindEntryAndValidate(context *ExprBuildContext) (sqlcore
 	}
 	tableBased, _ := entry.IsTableBased()
 	_, aliasBased := entry.(sqlcore.QueryAlias)
-	if tableBased == false && aliasBased == false {
+	_, namedBased := entry.(sqlcore.QueryNamed)
+	_, sqlValidOk := entry.(sqlcore.SqlReady)
+	// compound statement (union, intersect, except) let refer
+	// to own columns only in "order by" section
+	compoundOrderBy := sqlValidOk &&
+		context.SqlPartKind == sqlcore.SPK_SELECT_ORDER_BY
+	if tableBased == false && aliasBased == false && namedBased == false &&
+		!compoundOrderBy {
 		objstr, err := FormatPrettyDataSource(entry,
 			false, &context.Format.Dialect)
 		if err != nil {
@@ -262,22 +382,38 @@ func (this *TokenField) GetSql(context *ExprBuildContext) (*sqlcore.Statement, e
 		return nil, err
 	}
 	stat := sqlcore.NewStatement(sqlcore.SS_UNDEF)
-	if context.SqlPartKind == sqlcore.SPK_INSERT_RETURNING {
-		dialect := context.Format.Dialect
+	dialect := context.Format.Dialect
+	// PostgreSQL let refer to updated table by name, which avoid ambiguity
+	// with tables from "from" section, so such fields rendered as usual
+	if context.SqlPartKind.In(sqlcore.SPK_INSERT_RETURNING) ||
+		context.SqlPartKind.In(sqlcore.SPK_UPDATE_RETURNING|
+			sqlcore.SPK_DELETE_RETURNING) && dialect != sqldef.DI_PGSQL {
 		switch dialect {
-		case sqldef.DI_PGSQL:
+		case sqldef.DI_PGSQL, sqldef.DI_SQLITE:
 			stat.WriteString(f("%s",
 				context.Format.FormatObjectName(this.Name)))
 		case sqldef.DI_MSTSQL:
-			stat.WriteString(f("inserted.%s",
-				context.Format.FormatObjectName(this.Name)))
+			// deleted row available only as "deleted" pseudo table
+			if context.SqlPartKind == sqlcore.SPK_DELETE_RETURNING {
+				stat.WriteString(f("deleted.%s",
+					context.Format.FormatObjectName(this.Name)))
+			} else {
+				stat.WriteString(f("inserted.%s",
+					context.Format.FormatObjectName(this.Name)))
+			}
 		default:
 			return nil, e("Can't provide field specification "+
 				"for returning section in notation \"%v\"", dialect)
 		}
+	} else if context.SqlPartKind.In(sqlcore.SPK_CREATE_TABLE |
+		sqlcore.SPK_CREATE_INDEX) {
+		// check constraint and index condition
+		// could refer to own table columns only
+		stat.WriteString(context.Format.FormatObjectName(this.Name))
 	} else {
 		tableBased, table := entry.IsTableBased()
 		queryAlias, aliasBased := entry.(sqlcore.QueryAlias)
+		queryNamed, namedBased := entry.(sqlcore.QueryNamed)
 		if aliasBased {
 			stat.WriteString(f("%s.%s", queryAlias.GetAlias(),
 				context.Format.FormatObjectName(this.Name)))
@@ -285,6 +421,12 @@ func (this *TokenField) GetSql(context *ExprBuildContext) (*sqlcore.Statement, e
 			stat.WriteString(f("%s.%s",
 				context.Format.FormatTableName(table.GetName()),
 				context.Format.FormatObjectName(this.Name)))
+		} else if namedBased {
+			stat.WriteString(f("%s.%s",
+				context.Format.FormatObjectName(queryNamed.GetName()),
+				context.Format.FormatObjectName(this.Name)))
+		} else {
+			stat.WriteString(context.Format.FormatObjectName(this.Name))
 		}
 	}
 	return stat, nil
@@ -557,6 +699,8 @@ type TokenFunc struct {
 	Func       SqlFunc
 	CustomFunc *CustomFuncDef
 	Args       []Expr
+	// aggregate over distinct values: count(distinct expr)
+	Distinct bool
 }
 
 func (this *TokenFunc) getFuncTemplate(dialect sqldef.Dialect) *FuncTemplate {
@@ -577,20 +721,33 @@ func (this *TokenFunc) getFuncTemplate(dialect sqldef.Dialect) *FuncTemplate {
 		SF_GREAT_EQ:    bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} >= {1}", 2, 2))),
 		SF_NOT_EQ:      bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} <> {1}", 2, 2))),
 		SF_OR:          bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} or {1}", 2, 2))),
-		SF_IN:          bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} in ({1})", 2, 2))),
-		SF_NOT_IN:      bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} not in ({1})", 2, 2))),
+		SF_IN:          bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} in {1}", 2, 2))),
+		SF_NOT_IN:      bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} not in {1}", 2, 2))),
+		SF_EXISTS:      bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("exists {0}", 1, 1))),
+		SF_NOT_EXISTS:  bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("not exists {0}", 1, 1))),
 		SF_IS_NULL:     bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} is null", 1, 1))),
 		SF_IS_NOT_NULL: bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} is not null", 1, 1))),
 		SF_CASE_THEN_ELSE: bsfl(
 			bsf(sqldef.DI_MYSQL, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("case when {0} then {1} else {2} end case", 3, 3)),
 			bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("case when {0} then {1} else {2} end", 3, 3))),
-		SF_COALESCE: bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("coalesce({})", 1, -1))),
-		SF_ORD_ASC:  bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} asc", 1, 1))),
-		SF_ORD_DESC: bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} desc", 1, 1))),
-		SF_ADD:      bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0}+{1}", 2, 2))),
-		SF_SUBT:     bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0}-{1}", 2, 2))),
-		SF_MULT:     bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0}*{1}", 2, 2))),
-		SF_DIV:      bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0}/{1}", 2, 2))),
+		SF_COALESCE:        bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("coalesce({})", 1, -1))),
+		SF_ORD_ASC:         bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} asc", 1, 1))),
+		SF_ORD_DESC:        bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0} desc", 1, 1))),
+		SF_WIN_ROW_NUMBER:  bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("row_number()", 0, 0))),
+		SF_WIN_RANK:        bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("rank()", 0, 0))),
+		SF_WIN_DENSE_RANK:  bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("dense_rank()", 0, 0))),
+		SF_WIN_LAG:         bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("lag({})", 1, 3))),
+		SF_WIN_LEAD:        bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("lead({})", 1, 3))),
+		SF_WIN_FIRST_VALUE: bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("first_value({0})", 1, 1))),
+		SF_WIN_FRAME_ROWS:  bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("rows between {0} and {1}", 2, 2))),
+		SF_WIN_FRAME_RANGE: bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("range between {0} and {1}", 2, 2))),
+		// "groups" frame is not supported by MySQL and Microsoft T-SQL
+		SF_WIN_FRAME_GROUPS: bsfl(bsf(sqldef.DI_PGSQL|sqldef.DI_SQLITE, sqlcore.SPK_ANY,
+			sqlcore.SSPK_ANY, ft("groups between {0} and {1}", 2, 2))),
+		SF_ADD:  bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0}+{1}", 2, 2))),
+		SF_SUBT: bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0}-{1}", 2, 2))),
+		SF_MULT: bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0}*{1}", 2, 2))),
+		SF_DIV:  bsfl(bsf(sqldef.DI_ANY, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("{0}/{1}", 2, 2))),
 		// string functions
 		SF_TRIMSPACE: bsfl(
 			bsf(sqldef.DI_MSTSQL, sqlcore.SPK_ANY, sqlcore.SSPK_ANY, ft("ltrim(rtrim({0}))", 1, 1)),
@@ -679,6 +836,11 @@ func (this *TokenFunc) GetSql(context *ExprBuildContext) (*sqlcore.Statement, er
 		}
 		return nil, e("Custom function is undefined for dialect \"%v\"", dialect)
 	} else {
+		if !this.CheckContext(context.SqlPartKind,
+			context.SqlSubPartKind, context.Stack) {
+			return nil, e("Function \"%v\" can't be used in \"%v\" section",
+				this.Func, context.SqlPartKind)
+		}
 		fnc := this.getFuncTemplate(dialect)
 		if fnc != nil {
 			/*        if this.FlagsAny != SP_UNDEF &&
@@ -688,7 +850,20 @@ func (this *TokenFunc) GetSql(context *ExprBuildContext) (*sqlcore.Statement, er
 			          return nil, e("Functon \"%s\" can't be used in \"%v\" "+
 			              "without \"%v\"", this.Func, context.Flags, this.FlagsEach)
 			      }*/
-			stat, err := fnc.GetSql(context, this.Args...)
+			args := this.Args
+			if this.Distinct {
+				if !this.Func.In(SF_AGR_AVG | SF_AGR_COUNT |
+					SF_AGR_MAX | SF_AGR_MIN | SF_AGR_SUM) {
+					return nil, e("Function \"%v\" can't be used "+
+						"with \"distinct\" keyword", this.Func)
+				}
+				if len(args) != 1 {
+					return nil, e("Aggregate function \"%v\" with \"distinct\" "+
+						"keyword require exactly one argument", this.Func)
+				}
+				args = []Expr{&tokenDistinct{Expr: args[0]}}
+			}
+			stat, err := fnc.GetSql(context, args...)
 			if err != nil {
 				return nil, err
 			}
@@ -712,16 +887,44 @@ func (this *TokenFunc) CheckContext(sectionKind sqlcore.SqlPartKind,
 	subsectionKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack) bool {
 	switch this.Func {
 	case SF_AGR_AVG, SF_AGR_COUNT, SF_AGR_MAX, SF_AGR_MIN, SF_AGR_SUM:
-		if sectionKind != sqlcore.SPK_SELECT_GROUP_BY &&
-			stack.First(sqlcore.SPK_SELECT_GROUP_BY) != nil {
-			return true
-		} else {
-			return false
-		}
+		// aggregate functions allowed only in field list,
+		// "having" and "order by" sections of select statement
+		return sectionKind.In(sqlcore.SPK_SELECT |
+			sqlcore.SPK_SELECT_HAVING | sqlcore.SPK_SELECT_ORDER_BY)
+	case SF_WIN_ROW_NUMBER, SF_WIN_RANK, SF_WIN_DENSE_RANK,
+		SF_WIN_LAG, SF_WIN_LEAD, SF_WIN_FIRST_VALUE:
+		// window functions allowed only in field list
+		// and "order by" section of select statement
+		return sectionKind.In(sqlcore.SPK_SELECT | sqlcore.SPK_SELECT_ORDER_BY)
 	}
 	return true
 }
 
+// Argument of aggregate function prefixed with "distinct" keyword.
+type tokenDistinct struct {
+	Expr Expr
+}
+
+func (this *tokenDistinct) GetSql(context *ExprBuildContext) (*sqlcore.Statement, error) {
+	stat, err := this.Expr.GetSql(context)
+	if err != nil {
+		return nil, err
+	}
+	newst := sqlcore.NewStatement(sqlcore.SS_UNDEF)
+	newst.WriteString("distinct ")
+	newst.AppendStatPart(stat)
+	return newst, nil
+}
+
+func (this *tokenDistinct) CollectFields() []*TokenField {
+	return this.Expr.CollectFields()
+}
+
+func (this *tokenDistinct) CheckContext(sectionKind sqlcore.SqlPartKind,
+	subsectionKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack) bool {
+	return this.Expr.CheckContext(sectionKind, subsectionKind, stack)
+}
+
 type TokenFieldAlias struct {
 	Expr  Expr
 	Alias string
@@ -753,8 +956,7 @@ func (this *TokenFieldAlias) ContextFlagsEach() SqlPart {
 */
 func (this *TokenFieldAlias) CheckContext(sectionKind sqlcore.SqlPartKind,
 	subsectionKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack) bool {
-	// TODO
-	return true
+	return this.Expr.CheckContext(sectionKind, subsectionKind, stack)
 }
 
 func (this *TokenFieldAlias) GetFieldAliasOrName() string {
@@ -807,6 +1009,335 @@ func (this *TokenFieldAssign) GetSql(context *ExprBuildContext) (*sqlcore.Statem
 	return stat, nil
 }
 
+// Refer to the value proposed for insertion in conflict
+// resolution section of insert statement:
+//
+//	PostgreSQL, SQLite: excluded.column
+//	MySQL: values(column)
+//	Microsoft T-SQL: source alias of merge statement, excluded.column
+type TokenExcluded struct {
+	Field *TokenField
+}
+
+func (this *TokenExcluded) GetSql(context *ExprBuildContext) (*sqlcore.Statement, error) {
+	if !this.CheckContext(context.SqlPartKind,
+		context.SqlSubPartKind, context.Stack) {
+		return nil, e("Proposed value of column \"%s\" can't be used in \"%v\" section",
+			this.Field.Name, context.SqlPartKind)
+	}
+	_, err := this.Field.FindEntryAndValidate(context)
+	if err != nil {
+		return nil, err
+	}
+	stat := sqlcore.NewStatement(sqlcore.SS_UNDEF)
+	column := context.Format.FormatObjectName(this.Field.Name)
+	switch context.Format.Dialect {
+	case sqldef.DI_MYSQL:
+		stat.WriteString("values(%s)", column)
+	case sqldef.DI_PGSQL, sqldef.DI_SQLITE, sqldef.DI_MSTSQL:
+		stat.WriteString("%s.%s", context.Format.FormatObjectName("excluded"), column)
+	default:
+		return nil, e("Can't refer to proposed value of column \"%s\" "+
+			"in notation \"%v\"", this.Field.Name, context.Format.Dialect)
+	}
+	return stat, nil
+}
+
+func (this *TokenExcluded) CollectFields() []*TokenField {
+	return []*TokenField{this.Field}
+}
+
+// Proposed values allowed only in conflict resolution section.
+func (this *TokenExcluded) CheckContext(sectionKind sqlcore.SqlPartKind,
+	subsectionKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack) bool {
+	return sectionKind.In(sqlcore.SPK_INSERT_ON_CONFLICT)
+}
+
+type TokenExprList struct {
+	Exprs []Expr
+}
+
+func (this *TokenExprList) GetSql(context *ExprBuildContext) (*sqlcore.Statement, error) {
+	stat := sqlcore.NewStatement(sqlcore.SS_UNDEF)
+	stat.WriteString("(")
+	for i, expr := range this.Exprs {
+		stat2, err := expr.GetSql(context)
+		if err != nil {
+			return nil, err
+		}
+		stat.AppendStatPart(stat2)
+		if i < len(this.Exprs)-1 {
+			stat.WriteString(", ")
+		}
+	}
+	stat.WriteString(")")
+	return stat, nil
+}
+
+func (this *TokenExprList) CollectFields() []*TokenField {
+	var fields []*TokenField
+	for _, item := range this.Exprs {
+		f := item.CollectFields()
+		fields = append(fields, f...)
+	}
+	return fields
+}
+
+func (this *TokenExprList) CheckContext(sectionKind sqlcore.SqlPartKind,
+	subsectionKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack) bool {
+	return true
+}
+
+type TokenSubquery struct {
+	Query sqlcore.SqlReady
+	// Expected column count of subquery; 0 means any
+	ColumnCount int
+}
+
+func (this *TokenSubquery) validate(context *ExprBuildContext) error {
+	query, queryBased := this.Query.(sqlcore.Query)
+	if this.ColumnCount > 0 && queryBased &&
+		context.Format.ColumnNameAndCountValidationIsOn() {
+		c, err := query.GetColumnCount()
+		if err != nil {
+			return err
+		}
+		if c != this.ColumnCount {
+			return e("Subquery column count doesn't match "+
+				"expected one: %d <> %d", c, this.ColumnCount)
+		}
+	}
+	return nil
+}
+
+func (this *TokenSubquery) GetSql(context *ExprBuildContext) (*sqlcore.Statement, error) {
+	if this.Query == nil {
+		return nil, e("Subquery statement is nil")
+	}
+	err := this.validate(context)
+	if err != nil {
+		return nil, err
+	}
+	format := context.Format
+	// let subquery refer to data sources of outer statement
+	var queries []sqlcore.Query
+	if context.DataSources != nil {
+		queries = context.DataSources.Queries
+	}
+	format.PushOuterDataSources(queries)
+	format.IncIndentLevel()
+	batch, err := this.Query.GetSql(format)
+	format.DecIndentLevel()
+	format.PopOuterDataSources()
+	if err != nil {
+		return nil, err
+	}
+	if len(batch.Items) > 1 {
+		return nil, e("Can't use multiple sql statments "+
+			"in subquery: %v", batch)
+	}
+	stat := sqlcore.NewStatement(sqlcore.SS_UNDEF)
+	stat.WriteString("(")
+	stat.WriteString(format.SectionDivider)
+	stat.AppendStatPart(batch.Items[0])
+	stat.WriteString(format.SectionDivider)
+	stat.WriteString(format.GetLeadingSpace())
+	stat.WriteString(")")
+	return stat, nil
+}
+
+func (this *TokenSubquery) CollectFields() []*TokenField {
+	return []*TokenField{}
+}
+
+func (this *TokenSubquery) CheckContext(sectionKind sqlcore.SqlPartKind,
+	subsectionKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack) bool {
+	return true
+}
+
+type FrameBoundKind int
+
+const (
+	FBK_UNBOUNDED_PRECEDING FrameBoundKind = iota
+	FBK_PRECEDING
+	FBK_CURRENT_ROW
+	FBK_FOLLOWING
+	FBK_UNBOUNDED_FOLLOWING
+)
+
+// Start or end of window frame. Offset always rendered
+// as number, since Microsoft T-SQL doesn't allow parameters here.
+type TokenFrameBound struct {
+	Kind   FrameBoundKind
+	Offset int
+}
+
+func (this *TokenFrameBound) GetSql(context *ExprBuildContext) (*sqlcore.Statement, error) {
+	stat := sqlcore.NewStatement(sqlcore.SS_UNDEF)
+	switch this.Kind {
+	case FBK_UNBOUNDED_PRECEDING:
+		stat.WriteString("unbounded preceding")
+	case FBK_PRECEDING:
+		stat.WriteString("%d preceding", this.Offset)
+	case FBK_CURRENT_ROW:
+		stat.WriteString("current row")
+	case FBK_FOLLOWING:
+		stat.WriteString("%d following", this.Offset)
+	case FBK_UNBOUNDED_FOLLOWING:
+		stat.WriteString("unbounded following")
+	default:
+		return nil, e("Unknown window frame bound: %d", this.Kind)
+	}
+	if this.Offset < 0 {
+		return nil, e("Offset of window frame bound can't be negative: %d",
+			this.Offset)
+	}
+	return stat, nil
+}
+
+func (this *TokenFrameBound) CollectFields() []*TokenField {
+	return []*TokenField{}
+}
+
+func (this *TokenFrameBound) CheckContext(sectionKind sqlcore.SqlPartKind,
+	subsectionKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack) bool {
+	return true
+}
+
+// Window function or aggregate function followed by "over" clause:
+//
+//	func(...) over ([partition by ...] [order by ...] [frame])
+type TokenWindow struct {
+	Func      *TokenFunc
+	Partition []Expr
+	Order     []Expr
+	Frame     *TokenFunc
+}
+
+func (this *TokenWindow) PartitionBy(first Expr, rest ...Expr) *TokenWindow {
+	w := *this
+	w.Partition = append([]Expr{first}, rest...)
+	return &w
+}
+
+func (this *TokenWindow) OrderBy(first Expr, rest ...Expr) *TokenWindow {
+	w := *this
+	w.Order = append([]Expr{first}, rest...)
+	return &w
+}
+
+func (this *TokenWindow) makeFrame(function SqlFunc,
+	start, end *TokenFrameBound) *TokenWindow {
+	w := *this
+	w.Frame = &TokenFunc{Func: function, Args: []Expr{start, end}}
+	return &w
+}
+
+func (this *TokenWindow) Rows(start, end *TokenFrameBound) *TokenWindow {
+	return this.makeFrame(SF_WIN_FRAME_ROWS, start, end)
+}
+
+func (this *TokenWindow) Range(start, end *TokenFrameBound) *TokenWindow {
+	return this.makeFrame(SF_WIN_FRAME_RANGE, start, end)
+}
+
+func (this *TokenWindow) Groups(start, end *TokenFrameBound) *TokenWindow {
+	return this.makeFrame(SF_WIN_FRAME_GROUPS, start, end)
+}
+
+func (this *TokenWindow) writeExprList(context *ExprBuildContext,
+	stat *sqlcore.Statement, exprs []Expr) error {
+	for i, expr := range exprs {
+		stat2, err := expr.GetSql(context)
+		if err != nil {
+			return err
+		}
+		stat.AppendStatPart(stat2)
+		if i < len(exprs)-1 {
+			stat.WriteString(", ")
+		}
+	}
+	return nil
+}
+
+func (this *TokenWindow) GetSql(context *ExprBuildContext) (*sqlcore.Statement, error) {
+	if this.Func == nil {
+		return nil, e("Function of window expression is nil")
+	}
+	if !this.Func.Func.In(SF_WIN_ROW_NUMBER | SF_WIN_RANK | SF_WIN_DENSE_RANK |
+		SF_WIN_LAG | SF_WIN_LEAD | SF_WIN_FIRST_VALUE | SF_AGR_AVG |
+		SF_AGR_COUNT | SF_AGR_MAX | SF_AGR_MIN | SF_AGR_SUM) {
+		return nil, e("Function \"%v\" can't be used with \"over\" clause",
+			this.Func.Func)
+	}
+	// PostgreSQL and Microsoft T-SQL doesn't support
+	// "distinct" in aggregate functions with "over" clause
+	if this.Func.Distinct {
+		return nil, e("Function \"%v\" with \"distinct\" keyword "+
+			"can't be used with \"over\" clause", this.Func.Func)
+	}
+	if !this.CheckContext(context.SqlPartKind,
+		context.SqlSubPartKind, context.Stack) {
+		return nil, e("Window function \"%v\" can't be used in \"%v\" section",
+			this.Func.Func, context.SqlPartKind)
+	}
+	stat, err := this.Func.GetSql(context)
+	if err != nil {
+		return nil, err
+	}
+	stat.WriteString(" over (")
+	if len(this.Partition) > 0 {
+		stat.WriteString("partition by ")
+		err = this.writeExprList(context, stat, this.Partition)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(this.Order) > 0 {
+		if len(this.Partition) > 0 {
+			stat.WriteString(" ")
+		}
+		stat.WriteString("order by ")
+		err = this.writeExprList(context, stat, this.Order)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if this.Frame != nil {
+		if len(this.Partition) > 0 || len(this.Order) > 0 {
+			stat.WriteString(" ")
+		}
+		stat2, err := this.Frame.GetSql(context)
+		if err != nil {
+			return nil, err
+		}
+		stat.AppendStatPart(stat2)
+	}
+	stat.WriteString(")")
+	return stat, nil
+}
+
+func (this *TokenWindow) CollectFields() []*TokenField {
+	var fields []*TokenField
+	if this.Func != nil {
+		fields = append(fields, this.Func.CollectFields()...)
+	}
+	for _, item := range this.Partition {
+		fields = append(fields, item.CollectFields()...)
+	}
+	for _, item := range this.Order {
+		fields = append(fields, item.CollectFields()...)
+	}
+	return fields
+}
+
+// Window functions allowed only in field list
+// and "order by" section of select statement.
+func (this *TokenWindow) CheckContext(sectionKind sqlcore.SqlPartKind,
+	subsectionKind sqlcore.SqlSubPartKind, stack *sqlcore.CallStack) bool {
+	return sectionKind.In(sqlcore.SPK_SELECT | sqlcore.SPK_SELECT_ORDER_BY)
+}
+
 type TokenError struct {
 	Error error
 }
@@ -36,6 +36,13 @@ func (this *ExprFactory) Assign(field *TokenField, value Expr) *TokenFieldAssign
 	return exp
 }
 
+// Value proposed for insertion, used in conflict resolution
+// section of insert statement: excluded.column or values(column)
+func (this *ExprFactory) Excluded(field *TokenField) *TokenExcluded {
+	exp := &TokenExcluded{Field: field}
+	return exp
+}
+
 func (this *ExprFactory) makeFunc(function SqlFunc,
 	args ...Expr) *TokenFunc {
 	exp := &TokenFunc{Args: args, Func: function}
@@ -50,6 +57,8 @@ func (this *ExprFactory) convertToExpr(expr interface{}) Expr {
 		return this.Value(expr)
 	case time.Duration, time.Time:
 		return this.Value(expr)
+	case sqlcore.SqlReady:
+		return this.Subquery(expr.(sqlcore.SqlReady))
 	default:
 		return NewTokenError(e(
 			"Don't know how to convert the value to sql expression: %s", expr))
@@ -126,6 +135,19 @@ func (this *ExprFactory) Count(expr Expr) *TokenFunc {
 	return this.makeFunc(SF_AGR_COUNT, expr)
 }
 
+// agregate function: count(distinct ...)
+func (this *ExprFactory) CountDistinct(expr Expr) *TokenFunc {
+	return this.Distinct(this.Count(expr))
+}
+
+// aggregate function over distinct values only:
+// sum(distinct ...), avg(distinct ...) and so on
+func (this *ExprFactory) Distinct(fnc *TokenFunc) *TokenFunc {
+	exp := *fnc
+	exp.Distinct = true
+	return &exp
+}
+
 // agregate function: min()
 func (this *ExprFactory) Min(expr Expr) *TokenFunc {
 	return this.makeFunc(SF_AGR_MIN, expr)
@@ -141,6 +163,115 @@ func (this *ExprFactory) Average(expr Expr) *TokenFunc {
 	return this.makeFunc(SF_AGR_AVG, expr)
 }
 
+// "over" clause for aggregate or window function:
+// func(...) over (partition by ... order by ... frame)
+func (this *ExprFactory) Over(fnc *TokenFunc) *TokenWindow {
+	w := &TokenWindow{Func: fnc}
+	return w
+}
+
+// window function: row_number() over (...)
+func (this *ExprFactory) RowNumber() *TokenWindow {
+	return this.Over(this.makeFunc(SF_WIN_ROW_NUMBER))
+}
+
+// window function: rank() over (...)
+func (this *ExprFactory) Rank() *TokenWindow {
+	return this.Over(this.makeFunc(SF_WIN_RANK))
+}
+
+// window function: dense_rank() over (...)
+func (this *ExprFactory) DenseRank() *TokenWindow {
+	return this.Over(this.makeFunc(SF_WIN_DENSE_RANK))
+}
+
+// window function: lag(expr[, offset[, default]]) over (...)
+func (this *ExprFactory) Lag(expr Expr, args ...interface{}) *TokenWindow {
+	return this.Over(this.makeFunc(SF_WIN_LAG, this.makeArgs(expr, args...)...))
+}
+
+// window function: lead(expr[, offset[, default]]) over (...)
+func (this *ExprFactory) Lead(expr Expr, args ...interface{}) *TokenWindow {
+	return this.Over(this.makeFunc(SF_WIN_LEAD, this.makeArgs(expr, args...)...))
+}
+
+// window function: first_value(expr) over (...)
+func (this *ExprFactory) FirstValue(expr Expr) *TokenWindow {
+	return this.Over(this.makeFunc(SF_WIN_FIRST_VALUE, expr))
+}
+
+func (this *ExprFactory) makeArgs(first Expr, rest ...interface{}) []Expr {
+	args := []Expr{first}
+	for _, item := range rest {
+		args = append(args, this.convertToExpr(item))
+	}
+	return args
+}
+
+// window frame bound: unbounded preceding
+func (this *ExprFactory) UnboundedPreceding() *TokenFrameBound {
+	return &TokenFrameBound{Kind: FBK_UNBOUNDED_PRECEDING}
+}
+
+// window frame bound: <offset> preceding
+func (this *ExprFactory) Preceding(offset int) *TokenFrameBound {
+	return &TokenFrameBound{Kind: FBK_PRECEDING, Offset: offset}
+}
+
+// window frame bound: current row
+func (this *ExprFactory) CurrentRow() *TokenFrameBound {
+	return &TokenFrameBound{Kind: FBK_CURRENT_ROW}
+}
+
+// window frame bound: <offset> following
+func (this *ExprFactory) Following(offset int) *TokenFrameBound {
+	return &TokenFrameBound{Kind: FBK_FOLLOWING, Offset: offset}
+}
+
+// window frame bound: unbounded following
+func (this *ExprFactory) UnboundedFollowing() *TokenFrameBound {
+	return &TokenFrameBound{Kind: FBK_UNBOUNDED_FOLLOWING}
+}
+
+// sql operator: IN (subquery) or IN (expr1, expr2, ...)
+func (this *ExprFactory) In(expr Expr, first interface{}, rest ...interface{}) *TokenFunc {
+	return this.makeFunc(SF_IN, expr, this.makeInList(first, rest...))
+}
+
+// sql operator: NOT IN (subquery) or NOT IN (expr1, expr2, ...)
+func (this *ExprFactory) NotIn(expr Expr, first interface{}, rest ...interface{}) *TokenFunc {
+	return this.makeFunc(SF_NOT_IN, expr, this.makeInList(first, rest...))
+}
+
+func (this *ExprFactory) makeInList(first interface{}, rest ...interface{}) Expr {
+	if sel, ok := first.(sqlcore.SqlReady); ok && len(rest) == 0 {
+		return this.Subquery(sel)
+	}
+	exprs := []Expr{this.convertToExpr(first)}
+	for _, v := range rest {
+		exprs = append(exprs, this.convertToExpr(v))
+	}
+	return &TokenExprList{Exprs: exprs}
+}
+
+// sql operator: EXISTS (subquery)
+func (this *ExprFactory) Exists(sel sqlcore.SqlReady) *TokenFunc {
+	exp := &TokenSubquery{Query: sel}
+	return this.makeFunc(SF_EXISTS, exp)
+}
+
+// sql operator: NOT EXISTS (subquery)
+func (this *ExprFactory) NotExists(sel sqlcore.SqlReady) *TokenFunc {
+	exp := &TokenSubquery{Query: sel}
+	return this.makeFunc(SF_NOT_EXISTS, exp)
+}
+
+// scalar subquery, which should return single column
+func (this *ExprFactory) Subquery(sel sqlcore.SqlReady) *TokenSubquery {
+	exp := &TokenSubquery{Query: sel, ColumnCount: 1}
+	return exp
+}
+
 func (this *ExprFactory) IsNull(expr Expr) *TokenFunc {
 	return this.makeFunc(SF_IS_NULL, expr)
 }
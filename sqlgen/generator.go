@@ -0,0 +1,388 @@
+package sqlgen
+
+import (
+	"bytes"
+	"go/format"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+// Generator write Go source with typed struct per table,
+// which expose column of the table as *sqlexp.TokenField.
+// Struct embed table definition, so it could be used
+// as data source of statements:
+//
+//	sqlselect.NewSelect(Cust.Name).From(Cust).Where(ef.Equal(Cust.Id, 1))
+type Generator struct {
+	PackageName string
+	Tables      []*sqldb.TableDef
+	// Command line to mention in header of generated file.
+	Command string
+}
+
+func NewGenerator(packageName string, tables ...*sqldb.TableDef) *Generator {
+	g := &Generator{PackageName: packageName, Tables: tables}
+	return g
+}
+
+// Table with Go names of generated struct and its columns.
+type genTable struct {
+	Table    *sqldb.TableDef
+	TypeName string
+	VarName  string
+	Fields   []string
+}
+
+func (this *genTable) getFieldRef(field *sqldb.FieldDef) string {
+	return f("t.Fields.Find(%q)", field.Name)
+}
+
+// Convert database object name to exported Go identifier:
+// "cust_order" to "CustOrder".
+func getGoName(name string) string {
+	var buf bytes.Buffer
+	upper := true
+	for _, ch := range name {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+			upper = true
+			continue
+		}
+		if buf.Len() == 0 && unicode.IsDigit(ch) {
+			buf.WriteRune('T')
+		}
+		if upper {
+			buf.WriteRune(unicode.ToUpper(ch))
+			upper = false
+		} else {
+			buf.WriteRune(ch)
+		}
+	}
+	if buf.Len() == 0 {
+		buf.WriteRune('T')
+	}
+	return buf.String()
+}
+
+// Names which columns can't take, since they are promoted
+// from embedded table definition (fields and methods)
+// and required by interfaces.
+func getReservedNames() map[string]bool {
+	names := map[string]bool{"TableDef": true}
+	typ := reflect.TypeOf(&sqldb.TableDef{})
+	for i := 0; i < typ.NumMethod(); i++ {
+		names[typ.Method(i).Name] = true
+	}
+	for i := 0; i < typ.Elem().NumField(); i++ {
+		names[typ.Elem().Field(i).Name] = true
+	}
+	return names
+}
+
+func getUniqueName(name string, used map[string]bool, suffix string) string {
+	if used[name] {
+		name += suffix
+	}
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = f("%s%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+func (this *Generator) getGenTables() ([]*genTable, error) {
+	var tables []*genTable
+	usedTypes := map[string]bool{"Tables": true}
+	for _, table := range this.Tables {
+		for _, item := range tables {
+			if item.Table.Name == table.Name {
+				return nil, e("Table \"%s\" is specified twice", table.Name)
+			}
+		}
+		if len(table.Fields.Items) == 0 {
+			return nil, e("Table \"%s\" has no fields", table.Name)
+		}
+		name := getGoName(table.Name)
+		gt := &genTable{Table: table,
+			VarName:  getUniqueName(name, usedTypes, "Def"),
+			TypeName: getUniqueName(name+"Table", usedTypes, "Def")}
+		used := getReservedNames()
+		for _, field := range table.Fields.Items {
+			gt.Fields = append(gt.Fields,
+				getUniqueName(getGoName(field.Name), used, "Col"))
+		}
+		tables = append(tables, gt)
+	}
+	return tables, nil
+}
+
+func getDataTypeName(dataType sqldef.DataType) (string, error) {
+	names := map[sqldef.DataType]string{
+		sqldef.DT_INT_SMALL:       "DT_INT_SMALL",
+		sqldef.DT_INT:             "DT_INT",
+		sqldef.DT_INT_BIG:         "DT_INT_BIG",
+		sqldef.DT_NUMERIC:         "DT_NUMERIC",
+		sqldef.DT_DECIMAL:         "DT_DECIMAL",
+		sqldef.DT_REAL:            "DT_REAL",
+		sqldef.DT_DOUBLE:          "DT_DOUBLE",
+		sqldef.DT_FLOAT:           "DT_FLOAT",
+		sqldef.DT_UNICODE_CHAR:    "DT_UNICODE_CHAR",
+		sqldef.DT_UNICODE_VARCHAR: "DT_UNICODE_VARCHAR",
+		sqldef.DT_BOOL:            "DT_BOOL",
+		sqldef.DT_DATETIME:        "DT_DATETIME",
+		sqldef.DT_DATE:            "DT_DATE",
+		sqldef.DT_TIME:            "DT_TIME",
+		sqldef.DT_AUTOINC_INT:     "DT_AUTOINC_INT",
+		sqldef.DT_AUTOINC_INT_BIG: "DT_AUTOINC_INT_BIG",
+	}
+	if name, ok := names[dataType]; ok {
+		return name, nil
+	}
+	return "", e("Unknown data type \"%v\"", dataType)
+}
+
+func getForeignKeyActionName(action sqldb.ForeignKeyAction) string {
+	names := map[sqldb.ForeignKeyAction]string{
+		sqldb.FKA_NO_ACTION:   "FKA_NO_ACTION",
+		sqldb.FKA_RESTRICT:    "FKA_RESTRICT",
+		sqldb.FKA_CASCADE:     "FKA_CASCADE",
+		sqldb.FKA_SET_NULL:    "FKA_SET_NULL",
+		sqldb.FKA_SET_DEFAULT: "FKA_SET_DEFAULT",
+	}
+	return names[action]
+}
+
+// Return Go expression for default value; false,
+// if default value can't be expressed.
+func getDefaultValue(def *sqldb.DefaultDef) (string, bool) {
+	switch value := def.Value.(type) {
+	case nil:
+		return "nil", true
+	case *sqlexp.TokenValue:
+		switch value.Value.(type) {
+		case nil:
+			return "nil", true
+		case string, bool:
+			return f("%#v", value.Value), true
+		case int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64:
+			return f("%d", value.Value), true
+		case float32, float64:
+			return f("%#v", value.Value), true
+		}
+	case *sqlexp.TokenFunc:
+		funcs := map[sqlexp.SqlFunc]string{
+			sqlexp.SF_CURDATE:     "ef.CurrentDate()",
+			sqlexp.SF_CURTIME:     "ef.CurrentTime()",
+			sqlexp.SF_CURDATETIME: "ef.CurrentDateTime()",
+		}
+		if fnc, ok := funcs[value.Func]; ok {
+			return fnc, true
+		}
+	}
+	return "", false
+}
+
+func (this *Generator) writeFields(buf *bytes.Buffer, gt *genTable) error {
+	for _, field := range gt.Table.Fields.Items {
+		typeName, err := getDataTypeName(field.Data.Type)
+		if err != nil {
+			return e("Can't generate field \"%s\" of table \"%s\": %v",
+				field.Name, gt.Table.Name, err)
+		}
+		buf.WriteString(f("\tt.Fields.AddField(%q, sqldef.%s, %d, %d)",
+			field.Name, typeName, field.Data.Size1, field.Data.Size2))
+		if field.IsNullable {
+			buf.WriteString(".Null()")
+		} else {
+			buf.WriteString(".NotNull()")
+		}
+		if field.IsPrimaryKey {
+			buf.WriteString(".PrimaryKey()")
+		}
+		if field.Default != nil {
+			if value, ok := getDefaultValue(field.Default); ok {
+				buf.WriteString(f(".DefaultValue(%s)", value))
+			} else {
+				buf.WriteString(f("\n\t// default value of field %q "+
+					"is not generated", field.Name))
+			}
+		}
+		buf.WriteString("\n")
+	}
+	if gt.Table.PrimaryKeyName != "" {
+		buf.WriteString(f("\tt.PrimaryKeyName = %q\n", gt.Table.PrimaryKeyName))
+	}
+	return nil
+}
+
+func (this *Generator) writeConstraints(buf *bytes.Buffer, gt *genTable) {
+	for _, index := range gt.Table.Indexes.Items {
+		var refs, desc []string
+		for _, item := range index.Fields {
+			refs = append(refs, gt.getFieldRef(item.Field))
+			if item.Descending {
+				desc = append(desc, gt.getFieldRef(item.Field))
+			}
+		}
+		buf.WriteString(f("\tt.Indexes.AddIndex(%q, %s)", index.Name,
+			strings.Join(refs, ", ")))
+		if index.IsUnique {
+			buf.WriteString(".Unique()")
+		}
+		if len(desc) > 0 {
+			buf.WriteString(f(".Desc(%s)", strings.Join(desc, ", ")))
+		}
+		if index.Filter != nil {
+			buf.WriteString(f("\n\t// condition of index %q is not generated",
+				index.GetOrAdviceName(gt.Table)))
+		}
+		buf.WriteString("\n")
+	}
+	for _, unique := range gt.Table.Uniques.Items {
+		var refs []string
+		for _, field := range unique.Fields {
+			refs = append(refs, gt.getFieldRef(field))
+		}
+		buf.WriteString(f("\tt.Uniques.AddUnique(%q, %s)\n", unique.Name,
+			strings.Join(refs, ", ")))
+	}
+	for _, check := range gt.Table.Checks.Items {
+		buf.WriteString(f("\t// check constraint %q is not generated\n",
+			check.GetOrAdviceName(gt.Table)))
+	}
+}
+
+func (this *Generator) writeTable(buf *bytes.Buffer, gt *genTable) error {
+	buf.WriteString(f("// %s is generated from table %q.\n",
+		gt.TypeName, gt.Table.Name))
+	buf.WriteString(f("type %s struct {\n\t*sqldb.TableDef\n", gt.TypeName))
+	for _, name := range gt.Fields {
+		buf.WriteString(f("\t%s *sqlexp.TokenField\n", name))
+	}
+	buf.WriteString("}\n\n")
+	buf.WriteString(f("func new%s() *%s {\n", gt.TypeName, gt.TypeName))
+	buf.WriteString("\tef := sqlexp.Factory()\n")
+	buf.WriteString(f("\tt := sqldb.Table(%q)\n", gt.Table.Name))
+	err := this.writeFields(buf, gt)
+	if err != nil {
+		return err
+	}
+	this.writeConstraints(buf, gt)
+	buf.WriteString(f("\tr := &%s{TableDef: t}\n", gt.TypeName))
+	for i, field := range gt.Table.Fields.Items {
+		buf.WriteString(f("\tr.%s = ef.Field(t, %q)\n", gt.Fields[i], field.Name))
+	}
+	buf.WriteString("\treturn r\n}\n\n")
+	return nil
+}
+
+// Foreign keys are added, when all tables are created,
+// since tables could refer to each other.
+func (this *Generator) writeForeignKeys(buf *bytes.Buffer,
+	tables []*genTable) error {
+	var lines []string
+	for _, gt := range tables {
+		for _, fk := range gt.Table.ForeignKeys.Items {
+			var ref *genTable
+			for _, item := range tables {
+				if fk.RefTable != nil && item.Table.Name == fk.RefTable.Name {
+					ref = item
+					break
+				}
+			}
+			if ref == nil {
+				return e("Table referenced by foreign key \"%s\" "+
+					"is not generated", fk.GetOrAdviceName(gt.Table))
+			}
+			var refs []string
+			for _, field := range fk.Fields {
+				refs = append(refs, f("%s.TableDef.Fields.Find(%q)",
+					gt.VarName, field.Name))
+			}
+			line := f("\t%s.TableDef.ForeignKeys.AddForeignKey(%q, []*sqldb.FieldDef{%s}, %s.TableDef",
+				gt.VarName, fk.Name, strings.Join(refs, ", "), ref.VarName)
+			for _, field := range fk.RefFields {
+				line += f(", %s.TableDef.Fields.Find(%q)", ref.VarName, field.Name)
+			}
+			line += ")"
+			if fk.OnDelete != sqldb.FKA_NO_ACTION {
+				line += f(".OnDeleteDo(sqldb.%s)", getForeignKeyActionName(fk.OnDelete))
+			}
+			if fk.OnUpdate != sqldb.FKA_NO_ACTION {
+				line += f(".OnUpdateDo(sqldb.%s)", getForeignKeyActionName(fk.OnUpdate))
+			}
+			lines = append(lines, line+"\n")
+		}
+	}
+	if len(lines) > 0 {
+		buf.WriteString("func init() {\n")
+		for _, line := range lines {
+			buf.WriteString(line)
+		}
+		buf.WriteString("}\n\n")
+	}
+	return nil
+}
+
+// Generate write gofmt-ed Go source to writer.
+func (this *Generator) Generate(w io.Writer) error {
+	if this.PackageName == "" {
+		return e("Package name is not specified")
+	}
+	if len(this.Tables) == 0 {
+		return e("No tables specified to generate code")
+	}
+	tables, err := this.getGenTables()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by sqlgen. DO NOT EDIT.\n")
+	if this.Command != "" {
+		buf.WriteString(f("// Command: %s\n", this.Command))
+	}
+	buf.WriteString(f("\npackage %s\n\n", this.PackageName))
+	buf.WriteString("import (\n")
+	for _, pkg := range []string{"sqldb", "sqldef", "sqlexp"} {
+		buf.WriteString(f("\t%s\n", strconv.Quote("github.com/d2r2/sqlg/"+pkg)))
+	}
+	buf.WriteString(")\n\n")
+	buf.WriteString("var (\n")
+	for _, gt := range tables {
+		buf.WriteString(f("\t%s = new%s()\n", gt.VarName, gt.TypeName))
+	}
+	buf.WriteString(")\n\n")
+	buf.WriteString("// Tables lists generated tables in order of definition.\n")
+	buf.WriteString("var Tables = []*sqldb.TableDef{")
+	for i, gt := range tables {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(f("%s.TableDef", gt.VarName))
+	}
+	buf.WriteString("}\n\n")
+	for _, gt := range tables {
+		err = this.writeTable(&buf, gt)
+		if err != nil {
+			return err
+		}
+	}
+	err = this.writeForeignKeys(&buf, tables)
+	if err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return e("Can't format generated source: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
@@ -3,7 +3,8 @@ package sqlinsert
 // Generate sql statment according to general INSERT syntax:
 //      insert into table1
 //      [(column1, column2, ...)]
-//      values (value1, value2, ...)
+//      values (value1, value2, ...)[, (value1, value2, ...), ...]
+//      [on conflict (key1, key2, ...) do nothing | do update set ...]
 //      [returning expr1, expr2, ...]
 //
 //      or
@@ -51,7 +52,7 @@ func (this *ins) getInsertFieldCount() (int, error) {
 func (this *ins) Values(first sqlexp.Expr, last ...sqlexp.Expr) Values {
 	exprs := []sqlexp.Expr{first}
 	exprs = append(exprs, last...)
-	iv := &values{Root: this, Exprs: exprs}
+	iv := &values{Root: this, Rows: [][]sqlexp.Expr{exprs}}
 	return iv
 }
 
@@ -0,0 +1,85 @@
+package sqlinsert
+
+import (
+	"testing"
+
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+// Build insert of rows with 2 parameters each,
+// where dialect limit allows 5 parameters.
+func newInsertValues(rows int) (*sqldb.TableDef, Values) {
+	ef := sqlexp.Factory()
+	table := sqldb.Table("emp")
+	table.Fields.AddAutoinc("id")
+	table.Fields.AddInt("dept")
+	table.Fields.AddInt("salary")
+	ins := NewInsert(table, ef.Field(table, "dept"), ef.Field(table, "salary"))
+	values := ins.Values(ef.V(1), ef.V(1))
+	for i := 1; i < rows; i++ {
+		values = values.Values(ef.V(i+1), ef.V(i+1))
+	}
+	return table, values
+}
+
+func TestInsertSplitByParamLimit(t *testing.T) {
+	cases := []struct {
+		dialect sqldef.Dialect
+		rows    int
+		count   int
+	}{
+		{sqldef.DI_PGSQL, 2, 1},
+		{sqldef.DI_PGSQL, 3, 2},
+		{sqldef.DI_MYSQL, 5, 3},
+		{sqldef.DI_SQLITE, 4, 2},
+	}
+	for _, c := range cases {
+		format := sqlcore.NewFormat(c.dialect)
+		format.MaxParamCount = 5
+		_, values := newInsertValues(c.rows)
+		batch, err := values.GetSql(format)
+		if err != nil {
+			t.Errorf("%v, %d rows: unexpected error: %v", c.dialect, c.rows, err)
+			continue
+		}
+		if len(batch.Items) != c.count {
+			t.Errorf("%v, %d rows: expected %d statements, got %d",
+				c.dialect, c.rows, c.count, len(batch.Items))
+		}
+		for _, stat := range batch.Items {
+			if len(stat.Args) > format.MaxParamCount {
+				t.Errorf("%v, %d rows: statement exceed parameter limit: %d",
+					c.dialect, c.rows, len(stat.Args))
+			}
+		}
+	}
+}
+
+func TestInsertSplitWithReturning(t *testing.T) {
+	ef := sqlexp.Factory()
+	cases := []struct {
+		dialect sqldef.Dialect
+		rows    int
+		err     bool
+	}{
+		{sqldef.DI_PGSQL, 2, false},
+		{sqldef.DI_MYSQL, 2, false},
+		{sqldef.DI_PGSQL, 3, true},
+		{sqldef.DI_MYSQL, 3, true},
+		{sqldef.DI_SQLITE, 3, true},
+	}
+	for _, c := range cases {
+		format := sqlcore.NewFormat(c.dialect)
+		format.MaxParamCount = 5
+		table, values := newInsertValues(c.rows)
+		_, err := values.Returning(ef.Field(table, "id")).GetSql(format)
+		if c.err && err == nil {
+			t.Errorf("%v, %d rows: expected error", c.dialect, c.rows)
+		} else if !c.err && err != nil {
+			t.Errorf("%v, %d rows: unexpected error: %v", c.dialect, c.rows, err)
+		}
+	}
+}
@@ -12,15 +12,27 @@ type maker struct {
 	Batch            *sqlcore.StatementBatch
 	TargetDataSource sqlcore.Query
 	Returning        *returning
+	OnConflict       *onConflict
+	Values           *values
+	// Range of rows from "values" section to generate,
+	// when statement is split; 0 in RowTo means all rows.
+	RowFrom int
+	RowTo   int
+	// Parameter count of each row from "values" section.
+	RowArgCounts []int
+	// Statement, which contains "values" section rows.
+	ValuesStat *sqlcore.Statement
 }
 
 func (this *maker) CheckFieldAndExprCountMatch(sect *values) error {
 	r := sqlcore.GetSqlPartRoot(sect)
 	root := r.(*ins)
 	c1 := len(root.Fields)
-	if c1 != 0 {
-		c2 := len(sect.Exprs)
-		if c1 != c2 {
+	for _, row := range sect.Rows {
+		c2 := len(row)
+		if c1 == 0 {
+			c1 = c2
+		} else if c1 != c2 {
 			return e("Destination field count doesn't match "+
 				"select column count in INSERT statement: "+
 				"%d <> %d", c1, c2)
@@ -38,10 +50,14 @@ func (this *maker) runMaker(direct bool,
 			this.TargetDataSource = sect.DataSource
 		case sqlcore.SPK_INSERT_VALUES:
 			sect := part.(*values)
+			this.Values = sect
 			return this.CheckFieldAndExprCountMatch(sect)
 		case sqlcore.SPK_INSERT_RETURNING:
 			sect := part.(*returning)
 			this.Returning = sect
+		case sqlcore.SPK_INSERT_ON_CONFLICT:
+			sect := part.(*onConflict)
+			this.OnConflict = sect
 		case sqlcore.SPK_INSERT_FROM:
 			sect := part.(*from)
 			query, queryBased := sect.From.(sqlcore.Query)
@@ -71,13 +87,20 @@ func (this *maker) runMaker(direct bool,
 	} else {
 
 		var err error
+		// "merge" statement is completely generated in conflict
+		// resolution section, which has access to all insert parts
+		if this.OnConflict != nil && this.OnConflict.useMerge(this) &&
+			part.GetPartKind().In(sqlcore.SPK_INSERT|sqlcore.SPK_INSERT_VALUES) {
+			return nil
+		}
 		switch part.GetPartKind() {
 		case sqlcore.SPK_INSERT:
 			sect := part.(*ins)
 			err = sect.buildInsertSectionSql(this, this.Batch.Last(), stack, this.Returning)
 		case sqlcore.SPK_INSERT_VALUES:
 			sect := part.(*values)
-			err = sect.buildValuesSectionSql(this, this.Batch.Last(), stack)
+			this.ValuesStat = this.Batch.Last()
+			err = sect.buildValuesSectionSql(this, this.ValuesStat, stack)
 		case sqlcore.SPK_INSERT_RETURNING:
 			sect := part.(*returning)
 			ef := sqlexp.Factory()
@@ -101,6 +124,9 @@ func (this *maker) runMaker(direct bool,
 				stat := this.Batch.Last()
 				stat.Type = sqlcore.SS_QUERY
 			}
+		case sqlcore.SPK_INSERT_ON_CONFLICT:
+			sect := part.(*onConflict)
+			err = sect.buildOnConflictSectionSql(this, this.Batch.Last(), stack)
 		case sqlcore.SPK_INSERT_FROM:
 			sect := part.(*from)
 			err = sect.buildSelectSectionSql(this, this.Batch.Last(), stack)
@@ -113,15 +139,91 @@ func (this *maker) runMaker(direct bool,
 	return nil
 }
 
+func (this *maker) buildSql(part sqlcore.SqlPart) error {
+	this.Batch = sqlcore.NewStatementBatch()
+	this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
+	return sqlcore.IterateSqlParents(false, part, this.runMaker)
+}
+
+// Split rows of "values" section to chunks, so each statement
+// doesn't exceed parameter limit of dialect. Return nil,
+// if all rows fit to single statement.
+func (this *maker) splitRows() ([][2]int, error) {
+	if this.Values == nil || len(this.RowArgCounts) == 0 {
+		return nil, nil
+	}
+	maxParams := this.Format.MaxParamCount
+	// Microsoft T-SQL doesn't allow more than 1000 rows
+	// in "values" section of insert statement
+	maxRows := 0
+	if this.Format.Dialect == sqldef.DI_MSTSQL &&
+		(this.OnConflict == nil || !this.OnConflict.useMerge(this)) {
+		maxRows = 1000
+	}
+	// parameters from other sections repeat in each statement
+	fixed := len(this.ValuesStat.Args)
+	for _, c := range this.RowArgCounts {
+		fixed -= c
+	}
+	var chunks [][2]int
+	start, count := 0, fixed
+	for i, c := range this.RowArgCounts {
+		if maxParams > 0 && fixed+c > maxParams {
+			return nil, e("Row %d of INSERT statement exceed parameter "+
+				"limit of %v dialect: %d > %d", i+1, this.Format.Dialect,
+				fixed+c, maxParams)
+		}
+		if maxParams > 0 && count+c > maxParams ||
+			maxRows > 0 && i-start >= maxRows {
+			chunks = append(chunks, [2]int{start, i})
+			start, count = i, fixed
+		}
+		count += c
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	// rows returned by each statement (or last id selected
+	// after each statement) can't be combined to single result
+	if this.Returning != nil {
+		return nil, e("Can't split INSERT statement with RETURNING "+
+			"section to fit parameter limit of %v dialect, "+
+			"so reduce row count: %d", this.Format.Dialect,
+			len(this.RowArgCounts))
+	}
+	chunks = append(chunks, [2]int{start, len(this.RowArgCounts)})
+	return chunks, nil
+}
+
 func (this *maker) BuildSql(part sqlcore.SqlPart,
 	format *sqlcore.Format) error {
 	this.Format = format
-	this.Batch = sqlcore.NewStatementBatch()
-	this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
-	err := sqlcore.IterateSqlParents(false, part, this.runMaker)
+	paramIndex := format.GetParamIndex()
+	err := this.buildSql(part)
 	if err != nil {
 		return err
 	}
+	chunks, err := this.splitRows()
+	if err != nil {
+		return err
+	}
+	if chunks != nil {
+		batch := sqlcore.NewStatementBatch()
+		for _, chunk := range chunks {
+			this.RowFrom, this.RowTo = chunk[0], chunk[1]
+			// each statement has own parameter numbering
+			format.SetParamIndex(paramIndex)
+			err = this.buildSql(part)
+			if err != nil {
+				return err
+			}
+			batch.Items = append(batch.Items, this.Batch.Items...)
+		}
+		this.Batch = batch
+		// don't join statements, since joined one
+		// will exceed parameter limit again
+		return nil
+	}
 	err = this.Batch.Join(format)
 	return err
 }
@@ -0,0 +1,271 @@
+package sqlinsert
+
+// Conflict resolution section of insert statement. Rendered depending on dialect:
+//      PostgreSQL, SQLite:
+//          insert into ... values (...)
+//          on conflict (key1, key2, ...) do nothing | do update set ...
+//      MySQL:
+//          insert into ... values (...)
+//          on duplicate key update ...
+//      Microsoft T-SQL:
+//          merge into table1 using (values (...)) as excluded (column1, ...)
+//          on table1.key1 = excluded.key1 and ...
+//          [when matched then update set ...]
+//          when not matched then insert (column1, ...) values (excluded.column1, ...);
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+type OnConflict interface {
+	DoNothing() Upsert
+	DoUpdate(first *sqlexp.TokenFieldAssign,
+		rest ...*sqlexp.TokenFieldAssign) Upsert
+}
+
+type Upsert interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+	Returning(first sqlexp.Expr, rest ...sqlexp.Expr) *returning
+}
+
+type onConflict struct {
+	// parent
+	Values *values
+	// data
+	KeyFields []*sqlexp.TokenField
+	// when empty, conflicting row is left as is
+	Assigns []*sqlexp.TokenFieldAssign
+}
+
+func (this *onConflict) DoNothing() Upsert {
+	ic := *this
+	ic.Assigns = nil
+	return &ic
+}
+
+func (this *onConflict) DoUpdate(first *sqlexp.TokenFieldAssign,
+	rest ...*sqlexp.TokenFieldAssign) Upsert {
+	ic := *this
+	ic.Assigns = []*sqlexp.TokenFieldAssign{first}
+	ic.Assigns = append(ic.Assigns, rest...)
+	return &ic
+}
+
+func (this *onConflict) Returning(first sqlexp.Expr, rest ...sqlexp.Expr) *returning {
+	exprs := []sqlexp.Expr{first}
+	exprs = append(exprs, rest...)
+	ir := &returning{Upsert: this, Exprs: exprs}
+	return ir
+}
+
+// Microsoft T-SQL doesn't support conflict resolution in insert
+// statement, so whole statement is replaced with "merge" one.
+func (this *onConflict) useMerge(maker *maker) bool {
+	return maker.Format.Dialect == sqldef.DI_MSTSQL
+}
+
+func (this *onConflict) validate(maker *maker, context *sqlexp.ExprBuildContext) error {
+	if len(this.KeyFields) == 0 {
+		return e("Key fields of \"on conflict\" section are not specified")
+	}
+	for _, field := range this.KeyFields {
+		_, err := field.FindEntryAndValidate(context)
+		if err != nil {
+			return err
+		}
+	}
+	if this.useMerge(maker) {
+		root := this.Values.Root
+		if len(root.Fields) == 0 {
+			return e("%v dialect require destination fields to be "+
+				"specified to resolve conflict in insert statement",
+				maker.Format.Dialect)
+		}
+		for _, key := range this.KeyFields {
+			found := false
+			for _, field := range root.Fields {
+				if field.Name == key.Name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return e("Key field \"%s\" of \"on conflict\" section "+
+					"should be among destination fields", key.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (this *onConflict) buildAssignsSql(context *sqlexp.ExprBuildContext,
+	stat *sqlcore.Statement, assigns []*sqlexp.TokenFieldAssign) error {
+	for i, assign := range assigns {
+		stat2, err := assign.GetSql(context)
+		if err != nil {
+			return err
+		}
+		stat.AppendStatPart(stat2)
+		if i < len(assigns)-1 {
+			stat.WriteString(", ")
+		}
+	}
+	return nil
+}
+
+func (this *onConflict) buildOnConflictSectionSql(maker *maker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	context := maker.GetExprBuildContext(sqlcore.SPK_INSERT_ON_CONFLICT,
+		sqlcore.SSPK_EXPR1, stack, maker.Format)
+	err := this.validate(maker, context)
+	if err != nil {
+		return err
+	}
+	if this.useMerge(maker) {
+		return this.buildMergeSql(maker, stat, stack, context)
+	}
+	stat.WriteString(maker.Format.SectionDivider)
+	switch maker.Format.Dialect {
+	case sqldef.DI_PGSQL, sqldef.DI_SQLITE:
+		stat.WriteString("on conflict (")
+		for i, field := range this.KeyFields {
+			stat.WriteString(maker.Format.FormatObjectName(field.Name))
+			if i < len(this.KeyFields)-1 {
+				stat.WriteString(", ")
+			}
+		}
+		stat.WriteString(") ")
+		if len(this.Assigns) == 0 {
+			stat.WriteString("do nothing")
+		} else {
+			stat.WriteString("do update set ")
+			err = this.buildAssignsSql(context, stat, this.Assigns)
+			if err != nil {
+				return err
+			}
+		}
+	case sqldef.DI_MYSQL:
+		// MySQL detect conflict by any unique key of the table,
+		// so key fields used only to leave conflicting row as is
+		stat.WriteString("on duplicate key update ")
+		assigns := this.Assigns
+		if len(assigns) == 0 {
+			key := this.KeyFields[0]
+			assigns = []*sqlexp.TokenFieldAssign{
+				&sqlexp.TokenFieldAssign{Field: key, Value: key}}
+		}
+		err = this.buildAssignsSql(context, stat, assigns)
+		if err != nil {
+			return err
+		}
+	default:
+		return e("Can't produce \"on conflict\" section in notation \"%v\"",
+			maker.Format.Dialect)
+	}
+	return nil
+}
+
+func (this *onConflict) buildMergeSql(maker *maker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack,
+	context *sqlexp.ExprBuildContext) error {
+	root := this.Values.Root
+	format := maker.Format
+	target, err := format.FormatDataSourceRef(root.DataSource)
+	if err != nil {
+		return err
+	}
+	excluded := format.FormatObjectName("excluded")
+	var columns []string
+	for _, field := range root.Fields {
+		columns = append(columns, format.FormatObjectName(field.Name))
+	}
+	stat.AppendStatPartsFormat("merge into %s using ", target)
+	values := sqlcore.NewStatement(sqlcore.SS_UNDEF)
+	err = this.Values.buildValuesSectionSql(maker, values, stack)
+	if err != nil {
+		return err
+	}
+	maker.ValuesStat = stat
+	stat.WriteString("(")
+	stat.AppendStatPart(values)
+	stat.WriteString(format.SectionDivider)
+	stat.WriteString(") as %s (", excluded)
+	for i, column := range columns {
+		stat.WriteString(column)
+		if i < len(columns)-1 {
+			stat.WriteString(", ")
+		}
+	}
+	stat.WriteString(")")
+	stat.WriteString(format.SectionDivider)
+	stat.WriteString("on ")
+	for i, field := range this.KeyFields {
+		stat2, err := field.GetSql(context)
+		if err != nil {
+			return err
+		}
+		stat.AppendStatPart(stat2)
+		stat.WriteString(" = %s.%s", excluded, format.FormatObjectName(field.Name))
+		if i < len(this.KeyFields)-1 {
+			stat.WriteString(" and ")
+		}
+	}
+	if len(this.Assigns) > 0 {
+		stat.WriteString(format.SectionDivider)
+		stat.WriteString("when matched then update set ")
+		err = this.buildAssignsSql(context, stat, this.Assigns)
+		if err != nil {
+			return err
+		}
+	}
+	stat.WriteString(format.SectionDivider)
+	stat.WriteString("when not matched then insert (")
+	for i, column := range columns {
+		stat.WriteString(column)
+		if i < len(columns)-1 {
+			stat.WriteString(", ")
+		}
+	}
+	stat.WriteString(") values (")
+	for i, column := range columns {
+		stat.WriteString("%s.%s", excluded, column)
+		if i < len(columns)-1 {
+			stat.WriteString(", ")
+		}
+	}
+	stat.WriteString(")")
+	if maker.Returning != nil {
+		err = maker.Returning.buildReturningSectionSql(maker, stat, stack)
+		if err != nil {
+			return err
+		}
+	}
+	// "merge" statement must be terminated with semicolon
+	stat.WriteString(";")
+	return nil
+}
+
+func (this *onConflict) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &maker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, nil
+}
+
+func (this *onConflict) Validate(format *sqlcore.Format) error {
+	_, err := this.GetSql(format)
+	return err
+}
+
+func (this *onConflict) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_INSERT_ON_CONFLICT
+}
+
+func (this *onConflict) GetParent() sqlcore.SqlPart {
+	return this.Values
+}
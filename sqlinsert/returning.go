@@ -14,6 +14,7 @@ type Returning interface {
 type returning struct {
 	// parent
 	Values *values
+	Upsert *onConflict
 	// data
 	Exprs []sqlexp.Expr
 }
@@ -65,5 +66,9 @@ func (this *returning) GetPartKind() sqlcore.SqlPartKind {
 }
 
 func (this *returning) GetParent() sqlcore.SqlPart {
-	return this.Values
+	if this.Upsert != nil {
+		return this.Upsert
+	} else {
+		return this.Values
+	}
 }
@@ -8,14 +8,26 @@ import (
 type Values interface {
 	sqlcore.SqlReady
 	sqlcore.SqlPart
+	Values(first sqlexp.Expr, last ...sqlexp.Expr) Values
 	Returning(first sqlexp.Expr, rest ...sqlexp.Expr) *returning
+	OnConflict(first *sqlexp.TokenField, rest ...*sqlexp.TokenField) OnConflict
 }
 
 type values struct {
 	// parent
 	Root *ins
-	// data
-	Exprs []sqlexp.Expr
+	// data: one or more rows of expressions
+	Rows [][]sqlexp.Expr
+}
+
+// Add one more row to insert: values (...), (...), ...
+func (this *values) Values(first sqlexp.Expr, last ...sqlexp.Expr) Values {
+	exprs := []sqlexp.Expr{first}
+	exprs = append(exprs, last...)
+	rows := make([][]sqlexp.Expr, len(this.Rows), len(this.Rows)+1)
+	copy(rows, this.Rows)
+	iv := &values{Root: this.Root, Rows: append(rows, exprs)}
+	return iv
 }
 
 func (this *values) Returning(first sqlexp.Expr, rest ...sqlexp.Expr) *returning {
@@ -25,23 +37,51 @@ func (this *values) Returning(first sqlexp.Expr, rest ...sqlexp.Expr) *returning
 	return ir
 }
 
+// Key fields specify unique constraint, which violation
+// should be resolved with DoNothing or DoUpdate.
+func (this *values) OnConflict(first *sqlexp.TokenField,
+	rest ...*sqlexp.TokenField) OnConflict {
+	fields := []*sqlexp.TokenField{first}
+	fields = append(fields, rest...)
+	ic := &onConflict{Values: this, KeyFields: fields}
+	return ic
+}
+
 func (this *values) buildValuesSectionSql(maker *maker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
 	stat.WriteString(maker.Format.SectionDivider)
-	stat.WriteString("values (")
+	stat.WriteString("values ")
 	context := maker.GetExprBuildContext(sqlcore.SPK_INSERT_VALUES,
 		sqlcore.SSPK_EXPR1, stack, maker.Format)
-	for i, expr := range this.Exprs {
-		stat2, err := expr.GetSql(context)
-		if err != nil {
-			return err
+	rows := this.Rows
+	if maker.RowTo > 0 {
+		rows = rows[maker.RowFrom:maker.RowTo]
+	}
+	for j, row := range rows {
+		argCount := len(stat.Args)
+		stat.WriteString("(")
+		for i, expr := range row {
+			stat2, err := expr.GetSql(context)
+			if err != nil {
+				return err
+			}
+			stat.AppendStatPart(stat2)
+			if i < len(row)-1 {
+				stat.WriteString(", ")
+			}
+		}
+		stat.WriteString(")")
+		if j < len(rows)-1 {
+			stat.WriteString(",")
+			stat.WriteString(maker.Format.SectionDivider)
 		}
-		stat.AppendStatPart(stat2)
-		if i < len(this.Exprs)-1 {
-			stat.WriteString(", ")
+		// remember parameter count of each row, to split
+		// statement if dialect parameter limit is exceeded
+		if maker.RowTo == 0 {
+			maker.RowArgCounts = append(maker.RowArgCounts,
+				len(stat.Args)-argCount)
 		}
 	}
-	stat.WriteString(")")
 	return nil
 }
 
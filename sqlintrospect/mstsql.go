@@ -0,0 +1,217 @@
+package sqlintrospect
+
+import (
+	"database/sql"
+)
+
+// T-SQL structure read from sys catalog views.
+type mstsqlReader struct {
+}
+
+// Return expression for schema id and corresponding arguments;
+// when schema not specified, default one for user used.
+func (this *mstsqlReader) getSchemaExpr(reader *Reader) (string, []interface{}) {
+	if reader.SchemaName != nil {
+		return "schema_id(?)", []interface{}{*reader.SchemaName}
+	}
+	return "schema_id()", nil
+}
+
+func (this *mstsqlReader) readTableNames(reader *Reader) ([]string, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select t.name from sys.tables t "+
+		"where t.schema_id = %s order by t.name", schema), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (this *mstsqlReader) readColumns(reader *Reader,
+	table string) ([]*column, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select c.name, ty.name, c.max_length, "+
+		"c.precision, c.scale, c.is_nullable, dc.definition, c.is_identity "+
+		"from sys.columns c "+
+		"inner join sys.tables t on t.object_id = c.object_id "+
+		"inner join sys.types ty on ty.user_type_id = c.user_type_id "+
+		"left join sys.default_constraints dc on dc.object_id = c.default_object_id "+
+		"where t.schema_id = %s and t.name = ? "+
+		"order by c.column_id", schema), append(args, table)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []*column
+	for rows.Next() {
+		col := &column{}
+		var maxLength, precision, scale int
+		var def sql.NullString
+		if err := rows.Scan(&col.Name, &col.TypeName, &maxLength, &precision,
+			&scale, &col.IsNullable, &def, &col.IsAutoinc); err != nil {
+			return nil, err
+		}
+		switch col.TypeName {
+		case "char", "varchar", "nchar", "nvarchar":
+			if maxLength < 0 {
+				return nil, e("Can't map data type \"%s(max)\"", col.TypeName)
+			}
+			col.Size1 = maxLength
+			// max_length is specified in bytes
+			if col.TypeName[0] == 'n' {
+				col.Size1 = maxLength / 2
+			}
+		case "numeric", "decimal":
+			col.Size1, col.Size2 = precision, scale
+		case "float":
+			col.Size1 = precision
+		}
+		if def.Valid {
+			col.Default = &def.String
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+func (this *mstsqlReader) readPrimaryKey(reader *Reader,
+	table string) (string, []string, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select k.name, c.name "+
+		"from sys.key_constraints k "+
+		"inner join sys.tables t on t.object_id = k.parent_object_id "+
+		"inner join sys.index_columns ic on ic.object_id = k.parent_object_id "+
+		"and ic.index_id = k.unique_index_id "+
+		"inner join sys.columns c on c.object_id = ic.object_id "+
+		"and c.column_id = ic.column_id "+
+		"where k.type = 'PK' and t.schema_id = %s and t.name = ? "+
+		"order by ic.key_ordinal", schema), append(args, table)...)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+	var name string
+	var fields []string
+	for rows.Next() {
+		var field string
+		if err := rows.Scan(&name, &field); err != nil {
+			return "", nil, err
+		}
+		fields = append(fields, field)
+	}
+	return name, fields, rows.Err()
+}
+
+func (this *mstsqlReader) readIndexes(reader *Reader,
+	table string) ([]*index, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select i.name, i.is_unique, "+
+		"i.is_unique_constraint, i.filter_definition, c.name, ic.is_descending_key "+
+		"from sys.indexes i "+
+		"inner join sys.tables t on t.object_id = i.object_id "+
+		"inner join sys.index_columns ic on ic.object_id = i.object_id "+
+		"and ic.index_id = i.index_id "+
+		"inner join sys.columns c on c.object_id = ic.object_id "+
+		"and c.column_id = ic.column_id "+
+		"where i.is_primary_key = 0 and i.type > 0 and ic.is_included_column = 0 "+
+		"and t.schema_id = %s and t.name = ? "+
+		"order by i.name, ic.key_ordinal", schema), append(args, table)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []*index
+	var item *index
+	for rows.Next() {
+		var name string
+		var unique, constraint bool
+		var filter sql.NullString
+		field := &indexField{}
+		if err := rows.Scan(&name, &unique, &constraint, &filter,
+			&field.Name, &field.Descending); err != nil {
+			return nil, err
+		}
+		if item == nil || item.Name != name {
+			item = &index{Name: name, IsUnique: unique,
+				IsConstraint: constraint}
+			if filter.Valid {
+				item.Filter = &filter.String
+			}
+			indexes = append(indexes, item)
+		}
+		item.Fields = append(item.Fields, field)
+	}
+	return indexes, rows.Err()
+}
+
+func (this *mstsqlReader) readForeignKeys(reader *Reader,
+	table string) ([]*foreignKey, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select fk.name, rt.name, "+
+		"fk.delete_referential_action_desc, fk.update_referential_action_desc, "+
+		"c.name, rc.name "+
+		"from sys.foreign_keys fk "+
+		"inner join sys.tables t on t.object_id = fk.parent_object_id "+
+		"inner join sys.tables rt on rt.object_id = fk.referenced_object_id "+
+		"inner join sys.foreign_key_columns fkc on fkc.constraint_object_id = fk.object_id "+
+		"inner join sys.columns c on c.object_id = fkc.parent_object_id "+
+		"and c.column_id = fkc.parent_column_id "+
+		"inner join sys.columns rc on rc.object_id = fkc.referenced_object_id "+
+		"and rc.column_id = fkc.referenced_column_id "+
+		"where t.schema_id = %s and t.name = ? "+
+		"order by fk.name, fkc.constraint_column_id", schema),
+		append(args, table)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var fks []*foreignKey
+	var fk *foreignKey
+	for rows.Next() {
+		var name, refTable, onDelete, onUpdate, field, refField string
+		if err := rows.Scan(&name, &refTable, &onDelete, &onUpdate,
+			&field, &refField); err != nil {
+			return nil, err
+		}
+		if fk == nil || fk.Name != name {
+			fk = &foreignKey{Name: name, RefTable: refTable,
+				OnDelete: onDelete, OnUpdate: onUpdate}
+			fks = append(fks, fk)
+		}
+		fk.Fields = append(fk.Fields, field)
+		fk.RefFields = append(fk.RefFields, refField)
+	}
+	return fks, rows.Err()
+}
+
+func (this *mstsqlReader) readChecks(reader *Reader,
+	table string) ([]*check, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select cc.name, cc.definition "+
+		"from sys.check_constraints cc "+
+		"inner join sys.tables t on t.object_id = cc.parent_object_id "+
+		"where t.schema_id = %s and t.name = ? "+
+		"order by cc.name", schema), append(args, table)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var checks []*check
+	for rows.Next() {
+		item := &check{}
+		if err := rows.Scan(&item.Name, &item.Cond); err != nil {
+			return nil, err
+		}
+		checks = append(checks, item)
+	}
+	return checks, rows.Err()
+}
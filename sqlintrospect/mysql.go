@@ -0,0 +1,199 @@
+package sqlintrospect
+
+import (
+	"database/sql"
+)
+
+// MySQL structure read from information_schema (check constraints
+// are kept since MySQL 8.0.16). MySQL implement unique constraints
+// as unique indexes and doesn't distinguish them, so all are read
+// as indexes, which sqldiff consider equal to unique constraints.
+type mysqlReader struct {
+}
+
+// Return expression for schema name and corresponding arguments;
+// when schema not specified, current database used.
+func (this *mysqlReader) getSchemaExpr(reader *Reader) (string, []interface{}) {
+	if reader.SchemaName != nil {
+		return "?", []interface{}{*reader.SchemaName}
+	}
+	return "database()", nil
+}
+
+func (this *mysqlReader) readTableNames(reader *Reader) ([]string, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select table_name from information_schema.tables "+
+		"where table_schema = %s and table_type = 'BASE TABLE' "+
+		"order by table_name", schema), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (this *mysqlReader) readColumns(reader *Reader,
+	table string) ([]*column, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select column_name, column_type, "+
+		"is_nullable = 'YES', column_default, extra like '%%auto_increment%%' "+
+		"from information_schema.columns "+
+		"where table_schema = %s and table_name = ? "+
+		"order by ordinal_position", schema), append(args, table)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []*column
+	for rows.Next() {
+		col := &column{}
+		var typeName string
+		var def sql.NullString
+		if err := rows.Scan(&col.Name, &typeName, &col.IsNullable,
+			&def, &col.IsAutoinc); err != nil {
+			return nil, err
+		}
+		col.TypeName, col.Size1, col.Size2, err = parseTypeName(typeName)
+		if err != nil {
+			return nil, err
+		}
+		if def.Valid {
+			col.Default = &def.String
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+func (this *mysqlReader) readPrimaryKey(reader *Reader,
+	table string) (string, []string, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select column_name "+
+		"from information_schema.key_column_usage "+
+		"where table_schema = %s and table_name = ? "+
+		"and constraint_name = 'PRIMARY' order by ordinal_position", schema),
+		append(args, table)...)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+	var fields []string
+	for rows.Next() {
+		var field string
+		if err := rows.Scan(&field); err != nil {
+			return "", nil, err
+		}
+		fields = append(fields, field)
+	}
+	// primary key is always named "PRIMARY" in MySQL
+	return "", fields, rows.Err()
+}
+
+func (this *mysqlReader) readIndexes(reader *Reader,
+	table string) ([]*index, error) {
+	schema, args := this.getSchemaExpr(reader)
+	// skip indexes MySQL create implicitly for foreign keys
+	rows, err := reader.query(f("select s.index_name, s.non_unique = 0, "+
+		"s.column_name, coalesce(s.collation, 'A') = 'D' "+
+		"from information_schema.statistics s "+
+		"where s.table_schema = %s and s.table_name = ? "+
+		"and s.index_name <> 'PRIMARY' and not exists ("+
+		"select 1 from information_schema.table_constraints c "+
+		"where c.table_schema = s.table_schema and c.table_name = s.table_name "+
+		"and c.constraint_name = s.index_name "+
+		"and c.constraint_type = 'FOREIGN KEY') "+
+		"order by s.index_name, s.seq_in_index", schema),
+		append(args, table)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []*index
+	var item *index
+	for rows.Next() {
+		var name string
+		var unique bool
+		field := &indexField{}
+		if err := rows.Scan(&name, &unique, &field.Name,
+			&field.Descending); err != nil {
+			return nil, err
+		}
+		if item == nil || item.Name != name {
+			item = &index{Name: name, IsUnique: unique}
+			indexes = append(indexes, item)
+		}
+		item.Fields = append(item.Fields, field)
+	}
+	return indexes, rows.Err()
+}
+
+func (this *mysqlReader) readForeignKeys(reader *Reader,
+	table string) ([]*foreignKey, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select k.constraint_name, k.referenced_table_name, "+
+		"r.delete_rule, r.update_rule, k.column_name, k.referenced_column_name "+
+		"from information_schema.key_column_usage k "+
+		"inner join information_schema.referential_constraints r "+
+		"on r.constraint_schema = k.constraint_schema "+
+		"and r.table_name = k.table_name "+
+		"and r.constraint_name = k.constraint_name "+
+		"where k.table_schema = %s and k.table_name = ? "+
+		"and k.referenced_table_name is not null "+
+		"order by k.constraint_name, k.ordinal_position", schema),
+		append(args, table)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var fks []*foreignKey
+	var fk *foreignKey
+	for rows.Next() {
+		var name, refTable, onDelete, onUpdate, field, refField string
+		if err := rows.Scan(&name, &refTable, &onDelete, &onUpdate,
+			&field, &refField); err != nil {
+			return nil, err
+		}
+		if fk == nil || fk.Name != name {
+			fk = &foreignKey{Name: name, RefTable: refTable,
+				OnDelete: onDelete, OnUpdate: onUpdate}
+			fks = append(fks, fk)
+		}
+		fk.Fields = append(fk.Fields, field)
+		fk.RefFields = append(fk.RefFields, refField)
+	}
+	return fks, rows.Err()
+}
+
+func (this *mysqlReader) readChecks(reader *Reader,
+	table string) ([]*check, error) {
+	schema, args := this.getSchemaExpr(reader)
+	rows, err := reader.query(f("select c.constraint_name, cc.check_clause "+
+		"from information_schema.table_constraints c "+
+		"inner join information_schema.check_constraints cc "+
+		"on cc.constraint_schema = c.constraint_schema "+
+		"and cc.constraint_name = c.constraint_name "+
+		"where c.table_schema = %s and c.table_name = ? "+
+		"and c.constraint_type = 'CHECK' "+
+		"order by c.constraint_name", schema), append(args, table)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var checks []*check
+	for rows.Next() {
+		item := &check{}
+		if err := rows.Scan(&item.Name, &item.Cond); err != nil {
+			return nil, err
+		}
+		checks = append(checks, item)
+	}
+	return checks, rows.Err()
+}
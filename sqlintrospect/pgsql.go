@@ -0,0 +1,223 @@
+package sqlintrospect
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// PostgreSQL structure read from pg_catalog (PostgreSQL 10 and later).
+type pgsqlReader struct {
+}
+
+func (this *pgsqlReader) getSchemaName(reader *Reader) string {
+	if reader.SchemaName != nil {
+		return *reader.SchemaName
+	}
+	return *reader.Dialect.GetDefaultSchema()
+}
+
+func (this *pgsqlReader) readTableNames(reader *Reader) ([]string, error) {
+	rows, err := reader.query("select c.relname from pg_catalog.pg_class c "+
+		"inner join pg_catalog.pg_namespace n on n.oid = c.relnamespace "+
+		"where c.relkind = 'r' and n.nspname = ? order by c.relname",
+		this.getSchemaName(reader))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (this *pgsqlReader) readColumns(reader *Reader,
+	table string) ([]*column, error) {
+	rows, err := reader.query("select a.attname, "+
+		"pg_catalog.format_type(a.atttypid, a.atttypmod), not a.attnotnull, "+
+		"pg_catalog.pg_get_expr(d.adbin, d.adrelid), a.attidentity <> '' "+
+		"from pg_catalog.pg_attribute a "+
+		"inner join pg_catalog.pg_class c on c.oid = a.attrelid "+
+		"inner join pg_catalog.pg_namespace n on n.oid = c.relnamespace "+
+		"left join pg_catalog.pg_attrdef d on d.adrelid = a.attrelid and d.adnum = a.attnum "+
+		"where c.relname = ? and n.nspname = ? and a.attnum > 0 and not a.attisdropped "+
+		"order by a.attnum", table, this.getSchemaName(reader))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []*column
+	for rows.Next() {
+		col := &column{}
+		var typeName string
+		var def sql.NullString
+		var identity bool
+		if err := rows.Scan(&col.Name, &typeName, &col.IsNullable,
+			&def, &identity); err != nil {
+			return nil, err
+		}
+		col.TypeName, col.Size1, col.Size2, err = parseTypeName(typeName)
+		if err != nil {
+			return nil, err
+		}
+		if def.Valid {
+			col.Default = &def.String
+		}
+		// serial types are integers with default taken from sequence
+		col.IsAutoinc = identity || def.Valid &&
+			strings.HasPrefix(def.String, "nextval(")
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+func (this *pgsqlReader) readPrimaryKey(reader *Reader,
+	table string) (string, []string, error) {
+	rows, err := reader.query("select con.conname, a.attname "+
+		"from pg_catalog.pg_constraint con "+
+		"inner join pg_catalog.pg_class c on c.oid = con.conrelid "+
+		"inner join pg_catalog.pg_namespace n on n.oid = c.relnamespace "+
+		"cross join lateral unnest(con.conkey) with ordinality as k(attnum, ord) "+
+		"inner join pg_catalog.pg_attribute a on a.attrelid = con.conrelid "+
+		"and a.attnum = k.attnum "+
+		"where con.contype = 'p' and c.relname = ? and n.nspname = ? "+
+		"order by k.ord", table, this.getSchemaName(reader))
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+	var name string
+	var fields []string
+	for rows.Next() {
+		var field string
+		if err := rows.Scan(&name, &field); err != nil {
+			return "", nil, err
+		}
+		fields = append(fields, field)
+	}
+	return name, fields, rows.Err()
+}
+
+func (this *pgsqlReader) readIndexes(reader *Reader,
+	table string) ([]*index, error) {
+	rows, err := reader.query("select i.relname, x.indisunique, "+
+		"con.oid is not null, pg_catalog.pg_get_expr(x.indpred, x.indrelid), "+
+		"a.attname, (x.indoption[k.ord - 1] & 1) = 1 "+
+		"from pg_catalog.pg_index x "+
+		"inner join pg_catalog.pg_class c on c.oid = x.indrelid "+
+		"inner join pg_catalog.pg_namespace n on n.oid = c.relnamespace "+
+		"inner join pg_catalog.pg_class i on i.oid = x.indexrelid "+
+		"left join pg_catalog.pg_constraint con on con.conindid = x.indexrelid "+
+		"and con.contype = 'u' "+
+		"cross join lateral unnest(x.indkey::int2[]) with ordinality as k(attnum, ord) "+
+		"inner join pg_catalog.pg_attribute a on a.attrelid = x.indrelid "+
+		"and a.attnum = k.attnum "+
+		"where not x.indisprimary and c.relname = ? and n.nspname = ? "+
+		"order by i.relname, k.ord", table, this.getSchemaName(reader))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []*index
+	var item *index
+	for rows.Next() {
+		var name string
+		var unique, constraint bool
+		var filter sql.NullString
+		field := &indexField{}
+		if err := rows.Scan(&name, &unique, &constraint, &filter,
+			&field.Name, &field.Descending); err != nil {
+			return nil, err
+		}
+		if item == nil || item.Name != name {
+			item = &index{Name: name, IsUnique: unique,
+				IsConstraint: constraint}
+			if filter.Valid {
+				item.Filter = &filter.String
+			}
+			indexes = append(indexes, item)
+		}
+		item.Fields = append(item.Fields, field)
+	}
+	return indexes, rows.Err()
+}
+
+func (this *pgsqlReader) getForeignKeyAction(action string) string {
+	actions := map[string]string{
+		"a": "no action",
+		"r": "restrict",
+		"c": "cascade",
+		"n": "set null",
+		"d": "set default",
+	}
+	return actions[action]
+}
+
+func (this *pgsqlReader) readForeignKeys(reader *Reader,
+	table string) ([]*foreignKey, error) {
+	rows, err := reader.query("select con.conname, rc.relname, "+
+		"con.confdeltype, con.confupdtype, a.attname, ra.attname "+
+		"from pg_catalog.pg_constraint con "+
+		"inner join pg_catalog.pg_class c on c.oid = con.conrelid "+
+		"inner join pg_catalog.pg_namespace n on n.oid = c.relnamespace "+
+		"inner join pg_catalog.pg_class rc on rc.oid = con.confrelid "+
+		"cross join lateral unnest(con.conkey, con.confkey) "+
+		"with ordinality as k(attnum, refattnum, ord) "+
+		"inner join pg_catalog.pg_attribute a on a.attrelid = con.conrelid "+
+		"and a.attnum = k.attnum "+
+		"inner join pg_catalog.pg_attribute ra on ra.attrelid = con.confrelid "+
+		"and ra.attnum = k.refattnum "+
+		"where con.contype = 'f' and c.relname = ? and n.nspname = ? "+
+		"order by con.conname, k.ord", table, this.getSchemaName(reader))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var fks []*foreignKey
+	var fk *foreignKey
+	for rows.Next() {
+		var name, refTable, onDelete, onUpdate, field, refField string
+		if err := rows.Scan(&name, &refTable, &onDelete, &onUpdate,
+			&field, &refField); err != nil {
+			return nil, err
+		}
+		if fk == nil || fk.Name != name {
+			fk = &foreignKey{Name: name, RefTable: refTable,
+				OnDelete: this.getForeignKeyAction(onDelete),
+				OnUpdate: this.getForeignKeyAction(onUpdate)}
+			fks = append(fks, fk)
+		}
+		fk.Fields = append(fk.Fields, field)
+		fk.RefFields = append(fk.RefFields, refField)
+	}
+	return fks, rows.Err()
+}
+
+func (this *pgsqlReader) readChecks(reader *Reader,
+	table string) ([]*check, error) {
+	rows, err := reader.query("select con.conname, "+
+		"pg_catalog.pg_get_expr(con.conbin, con.conrelid) "+
+		"from pg_catalog.pg_constraint con "+
+		"inner join pg_catalog.pg_class c on c.oid = con.conrelid "+
+		"inner join pg_catalog.pg_namespace n on n.oid = c.relnamespace "+
+		"where con.contype = 'c' and c.relname = ? and n.nspname = ? "+
+		"order by con.conname", table, this.getSchemaName(reader))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var checks []*check
+	for rows.Next() {
+		item := &check{}
+		if err := rows.Scan(&item.Name, &item.Cond); err != nil {
+			return nil, err
+		}
+		checks = append(checks, item)
+	}
+	return checks, rows.Err()
+}
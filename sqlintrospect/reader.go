@@ -0,0 +1,289 @@
+package sqlintrospect
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+)
+
+// Column description read from database catalog.
+type column struct {
+	Name string
+	// lower case type name without size specification
+	TypeName   string
+	Size1      int
+	Size2      int
+	IsNullable bool
+	// default expression as stored in catalog; nil if not defined
+	Default   *string
+	IsAutoinc bool
+}
+
+type indexField struct {
+	Name       string
+	Descending bool
+}
+
+// Index or unique constraint description read from database catalog.
+type index struct {
+	Name         string
+	IsUnique     bool
+	IsConstraint bool
+	Fields       []*indexField
+	// condition of partial index as stored in catalog
+	Filter *string
+}
+
+type foreignKey struct {
+	Name      string
+	Fields    []string
+	RefTable  string
+	RefFields []string
+	OnDelete  string
+	OnUpdate  string
+}
+
+type check struct {
+	Name string
+	// condition as stored in catalog
+	Cond string
+}
+
+// Dialect specific catalog queries.
+type dialectReader interface {
+	readTableNames(reader *Reader) ([]string, error)
+	readColumns(reader *Reader, table string) ([]*column, error)
+	readPrimaryKey(reader *Reader, table string) (string, []string, error)
+	readIndexes(reader *Reader, table string) ([]*index, error)
+	readForeignKeys(reader *Reader, table string) ([]*foreignKey, error)
+	readChecks(reader *Reader, table string) ([]*check, error)
+}
+
+// Reader load structure of existing database tables
+// to table definitions, which let generate code from
+// live database or compare it with desired schema.
+// Conditions of check constraints and partial indexes
+// are kept as SQL text of the dialect.
+type Reader struct {
+	Dialect sqldef.Dialect
+	Db      *sql.DB
+	// schema to read tables from; when nil,
+	// default one for connection used
+	SchemaName *string
+}
+
+func NewReader(dialect sqldef.Dialect, db *sql.DB) *Reader {
+	r := &Reader{Dialect: dialect, Db: db}
+	return r
+}
+
+// ReadTables load structure of all tables
+// from database for specified dialect.
+func ReadTables(dialect sqldef.Dialect, db *sql.DB) ([]*sqldb.TableDef, error) {
+	return NewReader(dialect, db).ReadTables()
+}
+
+func (this *Reader) getDialectReader() (dialectReader, error) {
+	switch this.Dialect {
+	case sqldef.DI_PGSQL:
+		return &pgsqlReader{}, nil
+	case sqldef.DI_MYSQL:
+		return &mysqlReader{}, nil
+	case sqldef.DI_SQLITE:
+		return &sqliteReader{}, nil
+	case sqldef.DI_MSTSQL:
+		return &mstsqlReader{}, nil
+	default:
+		return nil, e("Can't read database structure "+
+			"for dialect \"%v\"", this.Dialect)
+	}
+}
+
+// Run catalog query, where "?" stands for parameter
+// and converted to dialect notation.
+func (this *Reader) query(sql string, args ...interface{}) (*sql.Rows, error) {
+	stat := sqlcore.NewStatement(sqlcore.SS_QUERY)
+	if this.Dialect == sqldef.DI_PGSQL {
+		i := 0
+		for _, ch := range sql {
+			if ch == '?' {
+				i++
+				stat.WriteString(f("$%d", i))
+			} else {
+				stat.WriteRune(ch)
+			}
+		}
+	} else {
+		stat.WriteString(sql)
+	}
+	stat.AppendArgs(args)
+	batch := sqlcore.NewStatementBatch()
+	batch.Add(stat)
+	return batch.Query(this.Db)
+}
+
+// ReadTables load structure of all tables, resolving
+// foreign key references between them.
+func (this *Reader) ReadTables() ([]*sqldb.TableDef, error) {
+	dr, err := this.getDialectReader()
+	if err != nil {
+		return nil, err
+	}
+	names, err := dr.readTableNames(this)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*sqldb.TableDef
+	for _, name := range names {
+		table, err := this.readTable(dr, name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	for i, name := range names {
+		fks, err := dr.readForeignKeys(this, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, fk := range fks {
+			err = this.addForeignKey(tables, tables[i], fk)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tables, nil
+}
+
+func (this *Reader) readTable(dr dialectReader, name string) (*sqldb.TableDef, error) {
+	table := sqldb.Table(name)
+	columns, err := dr.readColumns(this, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range columns {
+		field, err := getField(this.Dialect, col)
+		if err != nil {
+			return nil, e("Can't read column \"%s\" of table \"%s\": %v",
+				col.Name, name, err)
+		}
+		table.Fields.Items = append(table.Fields.Items, field)
+	}
+	pkName, pkFields, err := dr.readPrimaryKey(this, name)
+	if err != nil {
+		return nil, err
+	}
+	table.PrimaryKeyName = pkName
+	for _, fieldName := range pkFields {
+		field, err := findField(table, fieldName)
+		if err != nil {
+			return nil, err
+		}
+		field.IsPrimaryKey = true
+	}
+	indexes, err := dr.readIndexes(this, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range indexes {
+		var fields []*sqldb.FieldDef
+		for _, item2 := range item.Fields {
+			field, err := findField(table, item2.Name)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+		if item.IsConstraint {
+			table.Uniques.AddUnique(item.Name, fields...)
+			continue
+		}
+		index := table.Indexes.AddIndex(item.Name, fields...)
+		index.IsUnique = item.IsUnique
+		for i, item2 := range item.Fields {
+			index.Fields[i].Descending = item2.Descending
+		}
+		if item.Filter != nil {
+			index.Filter = getRawExpr(this.Dialect, *item.Filter)
+		}
+	}
+	checks, err := dr.readChecks(this, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range checks {
+		table.Checks.AddCheck(item.Name,
+			getRawExpr(this.Dialect, trimParentheses(item.Cond)))
+	}
+	return table, nil
+}
+
+func (this *Reader) addForeignKey(tables []*sqldb.TableDef,
+	table *sqldb.TableDef, fk *foreignKey) error {
+	var refTable *sqldb.TableDef
+	for _, item := range tables {
+		if item.Name == fk.RefTable {
+			refTable = item
+			break
+		}
+	}
+	if refTable == nil {
+		return e("Table \"%s\" referenced by foreign key \"%s\" "+
+			"is not found", fk.RefTable, fk.Name)
+	}
+	var fields, refFields []*sqldb.FieldDef
+	for _, name := range fk.Fields {
+		field, err := findField(table, name)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, field)
+	}
+	for _, name := range fk.RefFields {
+		field, err := findField(refTable, name)
+		if err != nil {
+			return err
+		}
+		refFields = append(refFields, field)
+	}
+	onDelete, err := getForeignKeyAction(fk.OnDelete)
+	if err != nil {
+		return err
+	}
+	onUpdate, err := getForeignKeyAction(fk.OnUpdate)
+	if err != nil {
+		return err
+	}
+	table.ForeignKeys.AddForeignKey(fk.Name, fields, refTable, refFields...).
+		OnDeleteDo(onDelete).OnUpdateDo(onUpdate)
+	return nil
+}
+
+func findField(table *sqldb.TableDef, name string) (*sqldb.FieldDef, error) {
+	field := table.Fields.Find(name)
+	if field == nil {
+		return nil, e("Field \"%s\" is not found in table \"%s\"",
+			name, table.Name)
+	}
+	return field, nil
+}
+
+func getForeignKeyAction(action string) (sqldb.ForeignKeyAction, error) {
+	// T-SQL use underscore in action names: SET_NULL
+	str := strings.ToLower(strings.Replace(action, "_", " ", -1))
+	if str == "" {
+		return sqldb.FKA_NO_ACTION, nil
+	}
+	for _, item := range []sqldb.ForeignKeyAction{sqldb.FKA_NO_ACTION,
+		sqldb.FKA_RESTRICT, sqldb.FKA_CASCADE, sqldb.FKA_SET_NULL,
+		sqldb.FKA_SET_DEFAULT} {
+		if item.String() == str {
+			return item, nil
+		}
+	}
+	return sqldb.FKA_NO_ACTION, e("Unknown foreign key action \"%s\"", action)
+}
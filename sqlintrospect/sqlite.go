@@ -0,0 +1,332 @@
+package sqlintrospect
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// SQLite keep structure in sqlite_master and expose it
+// via pragma table-valued functions (SQLite 3.16 and later).
+// Constraint names are not kept, so advised ones used instead,
+// except check constraints, which are parsed from table SQL.
+type sqliteReader struct {
+}
+
+func (this *sqliteReader) readTableNames(reader *Reader) ([]string, error) {
+	rows, err := reader.query("select name from sqlite_master " +
+		"where type = 'table' and name not like 'sqlite_%' order by name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (this *sqliteReader) readObjectSql(reader *Reader,
+	objType string, name string) (string, error) {
+	rows, err := reader.query("select sql from sqlite_master "+
+		"where type = ? and name = ?", objType, name)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var str sql.NullString
+	if rows.Next() {
+		if err := rows.Scan(&str); err != nil {
+			return "", err
+		}
+	}
+	return str.String, rows.Err()
+}
+
+func (this *sqliteReader) readColumns(reader *Reader,
+	table string) ([]*column, error) {
+	tableSql, err := this.readObjectSql(reader, "table", table)
+	if err != nil {
+		return nil, err
+	}
+	autoinc := strings.Contains(strings.ToLower(tableSql), "autoincrement")
+	rows, err := reader.query("select name, type, \"notnull\", dflt_value, pk "+
+		"from pragma_table_info(?) order by cid", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []*column
+	for rows.Next() {
+		col := &column{}
+		var typeName string
+		var notNull, pk int
+		var def sql.NullString
+		if err := rows.Scan(&col.Name, &typeName, &notNull, &def, &pk); err != nil {
+			return nil, err
+		}
+		col.TypeName, col.Size1, col.Size2, err = parseTypeName(typeName)
+		if err != nil {
+			return nil, err
+		}
+		col.IsNullable = notNull == 0
+		if def.Valid {
+			col.Default = &def.String
+		}
+		// only "integer primary key" column could be auto incremented
+		col.IsAutoinc = autoinc && pk > 0 &&
+			(col.TypeName == "integer" || col.TypeName == "bigint")
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+func (this *sqliteReader) readPrimaryKey(reader *Reader,
+	table string) (string, []string, error) {
+	rows, err := reader.query("select name from pragma_table_info(?) "+
+		"where pk > 0 order by pk", table)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+	var fields []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return "", nil, err
+		}
+		fields = append(fields, name)
+	}
+	return "", fields, rows.Err()
+}
+
+func (this *sqliteReader) readIndexes(reader *Reader,
+	table string) ([]*index, error) {
+	rows, err := reader.query("select name, \"unique\", origin, partial "+
+		"from pragma_index_list(?) where origin <> 'pk' order by seq desc", table)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []*index
+	var partials []bool
+	for rows.Next() {
+		item := &index{}
+		var unique, partial int
+		var origin string
+		if err := rows.Scan(&item.Name, &unique, &origin, &partial); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		item.IsUnique = unique != 0
+		// index created to support unique constraint
+		item.IsConstraint = origin == "u"
+		indexes = append(indexes, item)
+		partials = append(partials, partial != 0)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range indexes {
+		if partials[i] {
+			filter, err := this.readIndexFilter(reader, item.Name)
+			if err != nil {
+				return nil, err
+			}
+			item.Filter = &filter
+		}
+		err = this.readIndexFields(reader, item)
+		if err != nil {
+			return nil, err
+		}
+		// name of automatic index doesn't match constraint name
+		if item.IsConstraint {
+			item.Name = ""
+		}
+	}
+	return indexes, nil
+}
+
+func (this *sqliteReader) readIndexFilter(reader *Reader,
+	name string) (string, error) {
+	indexSql, err := this.readObjectSql(reader, "index", name)
+	if err != nil {
+		return "", err
+	}
+	i := strings.LastIndex(strings.ToLower(indexSql), " where ")
+	if i < 0 {
+		return "", e("Can't find condition of partial index \"%s\"", name)
+	}
+	return strings.TrimSpace(indexSql[i+len(" where "):]), nil
+}
+
+func (this *sqliteReader) readIndexFields(reader *Reader, item *index) error {
+	rows, err := reader.query("select name, \"desc\" from pragma_index_xinfo(?) "+
+		"where key = 1 order by seqno", item.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		field := &indexField{}
+		var desc int
+		if err := rows.Scan(&field.Name, &desc); err != nil {
+			return err
+		}
+		field.Descending = desc != 0
+		item.Fields = append(item.Fields, field)
+	}
+	return rows.Err()
+}
+
+func (this *sqliteReader) readForeignKeys(reader *Reader,
+	table string) ([]*foreignKey, error) {
+	rows, err := reader.query("select id, \"table\", \"from\", \"to\", "+
+		"on_update, on_delete from pragma_foreign_key_list(?) "+
+		"order by id, seq", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var fks []*foreignKey
+	lastId := -1
+	var fk *foreignKey
+	for rows.Next() {
+		var id int
+		var refTable, from, onUpdate, onDelete string
+		// referenced field is null, when primary key implied
+		var to sql.NullString
+		if err := rows.Scan(&id, &refTable, &from, &to,
+			&onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+		if id != lastId {
+			fk = &foreignKey{RefTable: refTable,
+				OnUpdate: onUpdate, OnDelete: onDelete}
+			fks = append(fks, fk)
+			lastId = id
+		}
+		fk.Fields = append(fk.Fields, from)
+		if to.Valid {
+			fk.RefFields = append(fk.RefFields, to.String)
+		}
+	}
+	return fks, rows.Err()
+}
+
+func (this *sqliteReader) readChecks(reader *Reader,
+	table string) ([]*check, error) {
+	tableSql, err := this.readObjectSql(reader, "table", table)
+	if err != nil {
+		return nil, err
+	}
+	return parseSqliteChecks(tableSql)
+}
+
+// Find end of quoted identifier or string literal
+// started at position i, where quote is doubled
+// to be escaped: "a""b", 'a”b', [a b].
+func skipQuoted(sql string, i int) (int, error) {
+	end := sql[i]
+	if end == '[' {
+		end = ']'
+	}
+	for j := i + 1; j < len(sql); j++ {
+		if sql[j] == end {
+			if j+1 < len(sql) && sql[j+1] == end && end != ']' {
+				j++
+				continue
+			}
+			return j, nil
+		}
+	}
+	return 0, e("Unterminated quoted text in \"%s\"", sql)
+}
+
+func isQuote(ch byte) bool {
+	return ch == '\'' || ch == '"' || ch == '`' || ch == '['
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= '0' && ch <= '9' ||
+		ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= 0x80
+}
+
+// Parse check constraints from "create table" statement:
+//
+//	create table table1 (
+//	    column1 int check (...),
+//	    constraint check1 check (...))
+func parseSqliteChecks(tableSql string) ([]*check, error) {
+	var checks []*check
+	depth := 0
+	// identifiers and keywords of current table element
+	var words []string
+	for i := 0; i < len(tableSql); i++ {
+		ch := tableSql[i]
+		switch {
+		case isQuote(ch):
+			j, err := skipQuoted(tableSql, i)
+			if err != nil {
+				return nil, err
+			}
+			if depth == 1 && ch != '\'' {
+				words = append(words, tableSql[i+1:j])
+			}
+			i = j
+		case isIdentChar(ch):
+			j := i
+			for j < len(tableSql) && isIdentChar(tableSql[j]) {
+				j++
+			}
+			if depth == 1 {
+				words = append(words, tableSql[i:j])
+			}
+			i = j - 1
+		case ch == '(':
+			n := len(words)
+			if depth != 1 || n == 0 || strings.ToLower(words[n-1]) != "check" {
+				depth++
+				continue
+			}
+			// find closing parenthesis of condition
+			start := i + 1
+			depth2 := 1
+			for depth2 > 0 {
+				i++
+				if i >= len(tableSql) {
+					return nil, e("Unterminated check condition in \"%s\"",
+						tableSql)
+				}
+				if isQuote(tableSql[i]) {
+					j, err := skipQuoted(tableSql, i)
+					if err != nil {
+						return nil, err
+					}
+					i = j
+				} else if tableSql[i] == '(' {
+					depth2++
+				} else if tableSql[i] == ')' {
+					depth2--
+				}
+			}
+			item := &check{Cond: strings.TrimSpace(tableSql[start:i])}
+			if n >= 3 && strings.ToLower(words[n-3]) == "constraint" {
+				item.Name = words[n-2]
+			}
+			checks = append(checks, item)
+		case ch == ')':
+			depth--
+		case ch == ',':
+			if depth == 1 {
+				words = nil
+			}
+		}
+	}
+	return checks, nil
+}
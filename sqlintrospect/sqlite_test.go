@@ -0,0 +1,49 @@
+package sqlintrospect
+
+import (
+	"testing"
+)
+
+func TestParseSqliteChecks(t *testing.T) {
+	cases := []struct {
+		sql    string
+		checks []check
+		err    bool
+	}{
+		{"create table t (a int, b varchar(10))", nil, false},
+		{"create table t (a int check (a > 0), b int)",
+			[]check{{"", "a > 0"}}, false},
+		{"CREATE TABLE t (a int,\n    CONSTRAINT ck_a CHECK(a in (1, 2)))",
+			[]check{{"ck_a", "a in (1, 2)"}}, false},
+		{"create table t (a text, constraint \"ck a\" check (a <> 'x) check (y'))",
+			[]check{{"ck a", "a <> 'x) check (y'"}}, false},
+		{"create table t (\"check\" int, constraint [ck] check ([check] > 0),\n" +
+			"    constraint ck2 check (\"check\" < 10))",
+			[]check{{"ck", "[check] > 0"}, {"ck2", "\"check\" < 10"}}, false},
+		{"create table t (a int check (a > (0)", nil, true},
+		{"create table t (a text default 'x)", nil, true},
+	}
+	for _, c := range cases {
+		checks, err := parseSqliteChecks(c.sql)
+		if c.err {
+			if err == nil {
+				t.Errorf("%q: expected error", c.sql)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.sql, err)
+			continue
+		}
+		if len(checks) != len(c.checks) {
+			t.Errorf("%q: expected %d checks, got %d",
+				c.sql, len(c.checks), len(checks))
+			continue
+		}
+		for i, item := range checks {
+			if *item != c.checks[i] {
+				t.Errorf("%q: expected %v, got %v", c.sql, c.checks[i], *item)
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package sqlintrospect
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+// Split type specification like "numeric(10,2)" or
+// "character varying(50)" to lower case name and sizes.
+func parseTypeName(spec string) (string, int, int, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	name := spec
+	var size1, size2 int
+	if i := strings.Index(spec, "("); i >= 0 {
+		j := strings.Index(spec, ")")
+		if j < i {
+			return "", 0, 0, e("Can't parse data type \"%s\"", spec)
+		}
+		name = strings.TrimSpace(spec[:i] + spec[j+1:])
+		sizes := strings.Split(spec[i+1:j], ",")
+		var err error
+		size1, err = strconv.Atoi(strings.TrimSpace(sizes[0]))
+		if err != nil {
+			return "", 0, 0, e("Can't parse data type \"%s\"", spec)
+		}
+		if len(sizes) > 1 {
+			size2, err = strconv.Atoi(strings.TrimSpace(sizes[1]))
+			if err != nil {
+				return "", 0, 0, e("Can't parse data type \"%s\"", spec)
+			}
+		}
+	}
+	// MySQL attributes, which is not part of type
+	for _, attr := range []string{" unsigned", " zerofill"} {
+		name = strings.Replace(name, attr, "", -1)
+	}
+	return name, size1, size2, nil
+}
+
+// Map type name used in database catalog to data definition.
+func getDataDef(dialect sqldef.Dialect, col *column) (*sqldef.DataDef, error) {
+	var dataType sqldef.DataType
+	size1, size2 := 0, 0
+	switch col.TypeName {
+	case "smallint", "int2", "tinyint":
+		dataType = sqldef.DT_INT_SMALL
+		// MySQL keep boolean as tinyint(1)
+		if dialect == sqldef.DI_MYSQL && col.TypeName == "tinyint" &&
+			col.Size1 == 1 {
+			dataType = sqldef.DT_BOOL
+		}
+	case "int", "integer", "int4", "mediumint":
+		dataType = sqldef.DT_INT
+	case "bigint", "int8":
+		dataType = sqldef.DT_INT_BIG
+	case "real", "float4":
+		dataType = sqldef.DT_REAL
+	case "double precision", "double", "float8":
+		dataType = sqldef.DT_DOUBLE
+	case "float":
+		switch {
+		// T-SQL keep float(53) as float of 53 bits precision
+		case dialect == sqldef.DI_MSTSQL && col.Size1 == 53:
+			dataType = sqldef.DT_DOUBLE
+		case col.Size1 == 0 && dialect == sqldef.DI_MYSQL:
+			dataType = sqldef.DT_REAL
+		case col.Size1 == 0:
+			dataType = sqldef.DT_DOUBLE
+		default:
+			dataType = sqldef.DT_FLOAT
+			size1 = col.Size1
+		}
+	case "numeric":
+		dataType = sqldef.DT_NUMERIC
+		size1, size2 = col.Size1, col.Size2
+	case "decimal":
+		dataType = sqldef.DT_DECIMAL
+		size1, size2 = col.Size1, col.Size2
+	case "char", "character", "nchar", "bpchar":
+		dataType = sqldef.DT_UNICODE_CHAR
+		size1 = col.Size1
+	case "varchar", "character varying", "nvarchar":
+		dataType = sqldef.DT_UNICODE_VARCHAR
+		size1 = col.Size1
+	case "bool", "boolean", "bit":
+		dataType = sqldef.DT_BOOL
+	case "datetime", "datetime2", "timestamp", "timestamp without time zone":
+		dataType = sqldef.DT_DATETIME
+	case "date":
+		dataType = sqldef.DT_DATE
+	case "time", "time without time zone":
+		dataType = sqldef.DT_TIME
+	default:
+		return nil, e("Can't map data type \"%s\"", col.TypeName)
+	}
+	if col.IsAutoinc {
+		switch dataType {
+		case sqldef.DT_INT, sqldef.DT_INT_SMALL:
+			dataType = sqldef.DT_AUTOINC_INT
+		case sqldef.DT_INT_BIG:
+			dataType = sqldef.DT_AUTOINC_INT_BIG
+		default:
+			return nil, e("Can't map auto increment data type \"%s\"",
+				col.TypeName)
+		}
+	}
+	return sqldef.NewDataDef(dataType, size1, size2), nil
+}
+
+func getField(dialect sqldef.Dialect, col *column) (*sqldb.FieldDef, error) {
+	data, err := getDataDef(dialect, col)
+	if err != nil {
+		return nil, err
+	}
+	field := sqldb.NewFieldDef(col.Name, data)
+	// SQLite report "integer primary key" column as nullable
+	field.IsNullable = col.IsNullable && !col.IsAutoinc
+	if col.Default != nil && !col.IsAutoinc {
+		field.Default = sqldb.NewDefaultDef(
+			parseDefault(dialect, *col.Default))
+	}
+	return field, nil
+}
+
+// Remove parentheses enclosing whole expression,
+// which T-SQL add to default values: ((0)).
+func trimParentheses(expr string) string {
+	for len(expr) >= 2 && expr[0] == '(' && expr[len(expr)-1] == ')' {
+		depth := 0
+		enclosing := true
+		for i, ch := range expr {
+			if ch == '(' {
+				depth++
+			} else if ch == ')' {
+				depth--
+				if depth == 0 && i < len(expr)-1 {
+					enclosing = false
+					break
+				}
+			}
+		}
+		if !enclosing {
+			break
+		}
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// Parse quoted string literal: 'abc', N'abc', followed
+// by optional PostgreSQL type cast: 'abc'::character varying.
+func parseQuoted(expr string) (string, bool) {
+	if strings.HasPrefix(expr, "N'") {
+		expr = expr[1:]
+	}
+	if !strings.HasPrefix(expr, "'") {
+		return "", false
+	}
+	var value []rune
+	quoted := false
+	end := -1
+	for i, ch := range expr[1:] {
+		if quoted {
+			quoted = false
+			if ch == '\'' {
+				value = append(value, ch)
+				continue
+			}
+			end = i + 1
+			break
+		}
+		if ch == '\'' {
+			quoted = true
+			continue
+		}
+		value = append(value, ch)
+	}
+	if quoted {
+		end = len(expr)
+	}
+	if end < 0 {
+		return "", false
+	}
+	rest := expr[end:]
+	if rest != "" && !strings.HasPrefix(rest, "::") {
+		return "", false
+	}
+	return string(value), true
+}
+
+// Convert default value stored in catalog either to constant
+// or expression; unrecognized expressions kept as is for the dialect.
+func parseDefault(dialect sqldef.Dialect, expr string) interface{} {
+	expr = trimParentheses(strings.TrimSpace(expr))
+	if value, ok := parseQuoted(expr); ok {
+		return value
+	}
+	str := strings.ToLower(expr)
+	if i := strings.Index(str, "::"); i >= 0 {
+		str = str[:i]
+	}
+	str = trimParentheses(str)
+	switch str {
+	case "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	case "current_timestamp", "current_timestamp()", "now()", "getdate()":
+		ef := sqlexp.Factory()
+		return ef.CurrentDateTime()
+	}
+	if value, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return value
+	}
+	if value, err := strconv.ParseFloat(str, 64); err == nil {
+		return value
+	}
+	// MySQL 8 keep string default values unquoted
+	if dialect == sqldef.DI_MYSQL {
+		return expr
+	}
+	return getRawExpr(dialect, expr)
+}
+
+// Wrap SQL text read from catalog to expression,
+// which is rendered as is for the dialect.
+func getRawExpr(dialect sqldef.Dialect, expr string) sqlexp.Expr {
+	ef := sqlexp.Factory()
+	fnc := ef.FuncDef(ef.FuncDialectDef(dialect, expr, 0, 0))
+	return ef.Func(fnc)
+}
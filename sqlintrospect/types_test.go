@@ -0,0 +1,126 @@
+package sqlintrospect
+
+import (
+	"testing"
+
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+func TestParseTypeName(t *testing.T) {
+	cases := []struct {
+		spec  string
+		name  string
+		size1 int
+		size2 int
+		err   bool
+	}{
+		{"int", "int", 0, 0, false},
+		{"numeric(10,2)", "numeric", 10, 2, false},
+		{"NUMERIC( 10 , 2 )", "numeric", 10, 2, false},
+		{"character varying(50)", "character varying", 50, 0, false},
+		{"int(11) unsigned", "int", 11, 0, false},
+		{"tinyint(1)", "tinyint", 1, 0, false},
+		{"varchar(abc)", "", 0, 0, true},
+		{"varchar)50(", "", 0, 0, true},
+	}
+	for _, c := range cases {
+		name, size1, size2, err := parseTypeName(c.spec)
+		if c.err {
+			if err == nil {
+				t.Errorf("parseTypeName(%q): error expected", c.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTypeName(%q): %v", c.spec, err)
+			continue
+		}
+		if name != c.name || size1 != c.size1 || size2 != c.size2 {
+			t.Errorf("parseTypeName(%q) = %q, %d, %d; want %q, %d, %d",
+				c.spec, name, size1, size2, c.name, c.size1, c.size2)
+		}
+	}
+}
+
+func TestTrimParentheses(t *testing.T) {
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{"((0))", "0"},
+		{"(N'abc')", "N'abc'"},
+		{"(getdate())", "getdate()"},
+		{"(1) + (2)", "(1) + (2)"},
+		{"()", ""},
+		{"abc", "abc"},
+	}
+	for _, c := range cases {
+		if got := trimParentheses(c.expr); got != c.want {
+			t.Errorf("trimParentheses(%q) = %q; want %q", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestParseQuoted(t *testing.T) {
+	cases := []struct {
+		expr  string
+		value string
+		ok    bool
+	}{
+		{"'x'::character varying", "x", true},
+		{"'abc'", "abc", true},
+		{"N'abc'", "abc", true},
+		{"'a''b'", "a'b", true},
+		{"''", "", true},
+		{"'a' || 'b'", "", false},
+		{"abc", "", false},
+		{"'abc", "", false},
+	}
+	for _, c := range cases {
+		value, ok := parseQuoted(c.expr)
+		if ok != c.ok || value != c.value {
+			t.Errorf("parseQuoted(%q) = %q, %v; want %q, %v",
+				c.expr, value, ok, c.value, c.ok)
+		}
+	}
+}
+
+func TestParseDefault(t *testing.T) {
+	cases := []struct {
+		dialect sqldef.Dialect
+		expr    string
+		want    interface{}
+	}{
+		{sqldef.DI_MSTSQL, "((0))", int64(0)},
+		{sqldef.DI_MSTSQL, "(N'abc')", "abc"},
+		{sqldef.DI_MSTSQL, "((1.5))", 1.5},
+		{sqldef.DI_PGSQL, "'x'::character varying", "x"},
+		{sqldef.DI_PGSQL, "NULL::character varying", nil},
+		{sqldef.DI_PGSQL, "true", true},
+		{sqldef.DI_PGSQL, "'-1.5'::numeric", "-1.5"},
+		{sqldef.DI_PGSQL, "(-1)", int64(-1)},
+		{sqldef.DI_SQLITE, "'a''b'", "a'b"},
+		{sqldef.DI_SQLITE, "FALSE", false},
+		{sqldef.DI_MYSQL, "abc", "abc"},
+	}
+	for _, c := range cases {
+		if got := parseDefault(c.dialect, c.expr); got != c.want {
+			t.Errorf("parseDefault(%v, %q) = %#v; want %#v",
+				c.dialect, c.expr, got, c.want)
+		}
+	}
+	// current time variants converted to function
+	for _, expr := range []string{"CURRENT_TIMESTAMP", "(getdate())",
+		"now()", "current_timestamp()"} {
+		fnc, ok := parseDefault(sqldef.DI_MSTSQL, expr).(*sqlexp.TokenFunc)
+		if !ok || fnc.Func != sqlexp.SF_CURDATETIME {
+			t.Errorf("parseDefault(%q): current datetime function expected", expr)
+		}
+	}
+	// unrecognized expression kept as is
+	if _, ok := parseDefault(sqldef.DI_PGSQL,
+		"nextval('seq'::regclass)").(sqlexp.Expr); !ok {
+		t.Errorf("parseDefault: raw expression expected")
+	}
+}
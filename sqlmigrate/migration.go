@@ -0,0 +1,149 @@
+package sqlmigrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/d2r2/sqlg/sqlcore"
+)
+
+// MigrationFunc produce statements to apply (or revert) migration.
+type MigrationFunc func() (sqlcore.SqlReady, error)
+
+// Migration describe single versioned database change.
+// Versions define order in which migrations are applied.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      MigrationFunc
+	Down    MigrationFunc
+}
+
+func NewMigration(version int64, name string,
+	up MigrationFunc, down MigrationFunc) *Migration {
+	m := &Migration{Version: version, Name: name,
+		Up: up, Down: down}
+	return m
+}
+
+func (this *Migration) String() string {
+	return f("%d %q", this.Version, this.Name)
+}
+
+func (this *Migration) getBatch(fnc MigrationFunc,
+	format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	s, err := fnc()
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, e("Migration %v produce no statements", this)
+	}
+	return s.GetSql(format)
+}
+
+func (this *Migration) GetUpSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	if this.Up == nil {
+		return nil, e("Migration %v doesn't define \"up\" step", this)
+	}
+	return this.getBatch(this.Up, format)
+}
+
+func (this *Migration) GetDownSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	if this.Down == nil {
+		return nil, e("Migration %v can't be reverted, "+
+			"since doesn't define \"down\" step", this)
+	}
+	return this.getBatch(this.Down, format)
+}
+
+// GetChecksum calculate hash of SQL generated by "up" step,
+// which let detect migrations edited after they have been applied.
+func (this *Migration) GetChecksum(format *sqlcore.Format) (string, error) {
+	batch, err := this.GetUpSql(format)
+	if err != nil {
+		return "", err
+	}
+	return getBatchChecksum(batch), nil
+}
+
+func getBatchChecksum(batch *sqlcore.StatementBatch) string {
+	h := sha256.New()
+	for _, stat := range batch.Items {
+		h.Write([]byte(stat.Sql()))
+		h.Write([]byte(f("\n%v\n", stat.Args)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sortMigrations(migrations []*Migration) ([]*Migration, error) {
+	items := make([]*Migration, len(migrations))
+	copy(items, migrations)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Version < items[j].Version
+	})
+	for i, item := range items {
+		if i > 0 && items[i-1].Version == item.Version {
+			return nil, e("Migrations %v and %v have same version",
+				items[i-1], item)
+		}
+	}
+	return items, nil
+}
+
+// Static create migration step from statements, known beforehand.
+func Static(items ...sqlcore.SqlReady) MigrationFunc {
+	return func() (sqlcore.SqlReady, error) {
+		return Sequence(items...), nil
+	}
+}
+
+type sequence struct {
+	Items []sqlcore.SqlReady
+}
+
+// Sequence combine statements to run one after another.
+func Sequence(items ...sqlcore.SqlReady) sqlcore.SqlReady {
+	r := &sequence{Items: items}
+	return r
+}
+
+func (this *sequence) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	batch := sqlcore.NewStatementBatch()
+	for _, item := range this.Items {
+		batch2, err := item.GetSql(format)
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range batch2.Items {
+			batch.Add(stat)
+		}
+	}
+	return batch, nil
+}
+
+type rawBatch struct {
+	Batch *sqlcore.StatementBatch
+}
+
+// RawBatch wrap statements already built (or written by hand)
+// to run them as part of migration.
+func RawBatch(batch *sqlcore.StatementBatch) sqlcore.SqlReady {
+	r := &rawBatch{Batch: batch}
+	return r
+}
+
+// RawSql wrap dialect specific SQL to run it as part of migration.
+func RawSql(sql string, args ...interface{}) sqlcore.SqlReady {
+	stat := sqlcore.NewStatement(sqlcore.SS_EXEC)
+	stat.WriteString(sql)
+	stat.AppendArgs(args)
+	batch := sqlcore.NewStatementBatch()
+	batch.Add(stat)
+	return RawBatch(batch)
+}
+
+func (this *rawBatch) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	return this.Batch, nil
+}
@@ -0,0 +1,453 @@
+package sqlmigrate
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/d2r2/sqlg"
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqlcreate"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqldelete"
+	"github.com/d2r2/sqlg/sqlexp"
+	"github.com/d2r2/sqlg/sqlinsert"
+	"github.com/d2r2/sqlg/sqlselect"
+	"github.com/d2r2/sqlg/sqltx"
+)
+
+const DefaultTableName = "sqlg_migrations"
+
+// AppliedMigration is a record from migration history table.
+type AppliedMigration struct {
+	Version  int64
+	Name     string
+	Checksum string
+}
+
+// Migrator apply and revert migrations, keeping track of them
+// in history table. Lock table (history table name with "_lock"
+// suffix) prevent concurrent migration of the same database.
+type Migrator struct {
+	Db         *sql.DB
+	Format     *sqlcore.Format
+	TableName  string
+	Migrations []*Migration
+	// Print SQL to Output instead of running migrations.
+	DryRun bool
+	Output io.Writer
+}
+
+func NewMigrator(db *sql.DB, format *sqlcore.Format,
+	migrations ...*Migration) *Migrator {
+	m := &Migrator{Db: db, Format: format,
+		TableName: DefaultTableName, Migrations: migrations,
+		Output: os.Stdout}
+	return m
+}
+
+func OpenMigrator(connInit sqlcore.ConnInit, dialect sqldef.Dialect,
+	dbName *string, migrations ...*Migration) (*Migrator, error) {
+	db, err := connInit.Open(dialect, dbName)
+	if err != nil {
+		return nil, err
+	}
+	format := sqlcore.NewFormat(dialect)
+	return NewMigrator(db, format, migrations...), nil
+}
+
+func (this *Migrator) Close() error {
+	return this.Db.Close()
+}
+
+func (this *Migrator) getHistoryTable() *sqldb.TableDef {
+	t := sqldb.Table(this.TableName)
+	t.Fields.AddField("version", sqldef.DT_INT_BIG, 0, 0).NotNull().PrimaryKey()
+	t.Fields.AddUnicodeVariable("name", 250).NotNull()
+	t.Fields.AddUnicodeFixed("checksum", 64).NotNull()
+	t.Fields.AddDateTime("applied_at").NotNull()
+	return t
+}
+
+func (this *Migrator) getLockTable() *sqldb.TableDef {
+	t := sqldb.Table(this.TableName + "_lock")
+	t.Fields.AddInt("id").NotNull().PrimaryKey()
+	t.Fields.AddDateTime("locked_at").NotNull()
+	return t
+}
+
+func (this *Migrator) exec(s sqlcore.SqlReady) error {
+	batch, err := s.GetSql(this.Format)
+	if err != nil {
+		return err
+	}
+	_, err = batch.Exec(this.Db)
+	return err
+}
+
+func (this *Migrator) historyExists() (bool, error) {
+	batch, err := sqlg.Utils.CheckStatIfTableExists(
+		this.Format.Dialect, this.TableName)
+	if err != nil {
+		return false, err
+	}
+	row, err := batch.ExecQueryRow(this.Db)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return false, err
+	}
+	return count != 0, nil
+}
+
+func (this *Migrator) createHistory() error {
+	format := *this.Format
+	format.AddOptions(sqlcore.BO_DO_IF_OBJECT_EXISTS_NOT_EXISTS)
+	for _, t := range []*sqldb.TableDef{this.getHistoryTable(),
+		this.getLockTable()} {
+		batch, err := sqlcreate.NewCreateTable(t).GetSql(&format)
+		if err != nil {
+			return err
+		}
+		_, err = batch.Exec(this.Db)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *Migrator) lock() error {
+	ef := sqlexp.Factory()
+	t := this.getLockTable()
+	s := sqlinsert.NewInsert(t, ef.Field(t, "id"), ef.Field(t, "locked_at")).
+		Values(ef.Value(1), ef.Value(time.Now()))
+	err := this.exec(s)
+	if err != nil {
+		s2 := sqlselect.NewSelect(ef.Count(ef.Field(t, "id"))).From(t)
+		batch, err2 := s2.GetSql(this.Format)
+		if err2 != nil {
+			return err2
+		}
+		row, err2 := batch.ExecQueryRow(this.Db)
+		if err2 != nil {
+			return err2
+		}
+		var count int
+		if err2 := row.Scan(&count); err2 != nil {
+			return err2
+		}
+		if count != 0 {
+			return e("Database is locked by another migration process; "+
+				"if it is not running anymore, remove lock with ForceUnlock: %v",
+				err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (this *Migrator) unlock() error {
+	ef := sqlexp.Factory()
+	t := this.getLockTable()
+	s := sqldelete.NewDelete(t).Where(ef.Equal(ef.Field(t, "id"), 1))
+	return this.exec(s)
+}
+
+// ForceUnlock remove lock left by migration process
+// terminated abnormally.
+func (this *Migrator) ForceUnlock() error {
+	err := this.createHistory()
+	if err != nil {
+		return err
+	}
+	return this.unlock()
+}
+
+// GetApplied read migration history ordered by version.
+func (this *Migrator) GetApplied() ([]*AppliedMigration, error) {
+	if this.DryRun {
+		exists, err := this.historyExists()
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, nil
+		}
+	} else {
+		err := this.createHistory()
+		if err != nil {
+			return nil, err
+		}
+	}
+	ef := sqlexp.Factory()
+	t := this.getHistoryTable()
+	s := sqlselect.NewSelect(ef.Field(t, "version"), ef.Field(t, "name"),
+		ef.Field(t, "checksum")).From(t).OrderBy(ef.Field(t, "version"))
+	batch, err := s.GetSql(this.Format)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := batch.Query(this.Db)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var applied []*AppliedMigration
+	for rows.Next() {
+		item := &AppliedMigration{}
+		err = rows.Scan(&item.Version, &item.Name, &item.Checksum)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, item)
+	}
+	return applied, rows.Err()
+}
+
+func (this *Migrator) findMigration(version int64) *Migration {
+	for _, item := range this.Migrations {
+		if item.Version == version {
+			return item
+		}
+	}
+	return nil
+}
+
+func (this *Migrator) verify(applied []*AppliedMigration) error {
+	for _, item := range applied {
+		m := this.findMigration(item.Version)
+		if m == nil {
+			return e("Applied migration %d %q is not found "+
+				"in migration list", item.Version, item.Name)
+		}
+		checksum, err := m.GetChecksum(this.Format)
+		if err != nil {
+			return err
+		}
+		if checksum != item.Checksum {
+			return e("Migration %v was changed after it had been applied "+
+				"(checksum %s, expected %s)", m, checksum, item.Checksum)
+		}
+	}
+	return nil
+}
+
+// Verify check that every applied migration is still in the list
+// and was not edited since.
+func (this *Migrator) Verify() error {
+	applied, err := this.GetApplied()
+	if err != nil {
+		return err
+	}
+	return this.verify(applied)
+}
+
+// GetPending return migrations not applied yet, ordered by version.
+func (this *Migrator) GetPending() ([]*Migration, error) {
+	applied, err := this.GetApplied()
+	if err != nil {
+		return nil, err
+	}
+	return this.getPending(applied)
+}
+
+func (this *Migrator) getPending(applied []*AppliedMigration) ([]*Migration, error) {
+	migrations, err := sortMigrations(this.Migrations)
+	if err != nil {
+		return nil, err
+	}
+	var pending []*Migration
+	for _, item := range migrations {
+		found := false
+		for _, item2 := range applied {
+			if item2.Version == item.Version {
+				found = true
+				break
+			}
+		}
+		if !found {
+			pending = append(pending, item)
+		}
+	}
+	return pending, nil
+}
+
+func (this *Migrator) print(m *Migration, step string,
+	batch *sqlcore.StatementBatch) {
+	w := this.Output
+	io.WriteString(w, f("-- migration %v: %s\n", m, step))
+	for _, stat := range batch.Items {
+		io.WriteString(w, stat.Sql())
+		io.WriteString(w, ";\n")
+		if len(stat.Args) > 0 {
+			io.WriteString(w, f("-- args: %v\n", stat.Args))
+		}
+	}
+}
+
+func (this *Migrator) run(fnc func() error) error {
+	if this.DryRun {
+		return fnc()
+	}
+	err := this.createHistory()
+	if err != nil {
+		return err
+	}
+	err = this.lock()
+	if err != nil {
+		return err
+	}
+	err = fnc()
+	err2 := this.unlock()
+	if err != nil {
+		return err
+	}
+	return err2
+}
+
+// Run migration batch together with history record update in single
+// transaction, so failed migration leave neither its changes nor
+// history record. Keep in mind, that MySQL implicitly commit
+// transaction on most of DDL statements, so they can't be rolled back.
+func (this *Migrator) applyTx(batch *sqlcore.StatementBatch,
+	history sqlcore.SqlReady) error {
+	ctx := context.Background()
+	return sqltx.RunTx(ctx, this.Db, this.Format, nil, func(tx *sqltx.Tx) error {
+		_, err := batch.ExecContext(ctx, tx)
+		if err != nil {
+			return err
+		}
+		batch2, err := history.GetSql(this.Format)
+		if err != nil {
+			return err
+		}
+		_, err = batch2.ExecContext(ctx, tx)
+		return err
+	})
+}
+
+func (this *Migrator) applyUp(m *Migration) error {
+	batch, err := m.GetUpSql(this.Format)
+	if err != nil {
+		return err
+	}
+	if this.DryRun {
+		this.print(m, "up", batch)
+		return nil
+	}
+	log.Infof("Apply migration %v", m)
+	ef := sqlexp.Factory()
+	t := this.getHistoryTable()
+	s := sqlinsert.NewInsert(t, ef.Field(t, "version"), ef.Field(t, "name"),
+		ef.Field(t, "checksum"), ef.Field(t, "applied_at")).
+		Values(ef.Value(m.Version), ef.Value(m.Name),
+			ef.Value(getBatchChecksum(batch)), ef.Value(time.Now()))
+	err = this.applyTx(batch, s)
+	if err != nil {
+		return e("Migration %v failed: %v", m, err)
+	}
+	return nil
+}
+
+func (this *Migrator) applyDown(m *Migration) error {
+	batch, err := m.GetDownSql(this.Format)
+	if err != nil {
+		return err
+	}
+	if this.DryRun {
+		this.print(m, "down", batch)
+		return nil
+	}
+	log.Infof("Revert migration %v", m)
+	ef := sqlexp.Factory()
+	t := this.getHistoryTable()
+	s := sqldelete.NewDelete(t).Where(ef.Equal(ef.Field(t, "version"), m.Version))
+	err = this.applyTx(batch, s)
+	if err != nil {
+		return e("Migration %v failed to revert: %v", m, err)
+	}
+	return nil
+}
+
+// UpTo apply pending migrations with version less or equal to specified.
+func (this *Migrator) UpTo(version int64) error {
+	return this.run(func() error {
+		applied, err := this.GetApplied()
+		if err != nil {
+			return err
+		}
+		err = this.verify(applied)
+		if err != nil {
+			return err
+		}
+		pending, err := this.getPending(applied)
+		if err != nil {
+			return err
+		}
+		for _, item := range pending {
+			if item.Version > version {
+				break
+			}
+			err = this.applyUp(item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Up apply all pending migrations.
+func (this *Migrator) Up() error {
+	migrations, err := sortMigrations(this.Migrations)
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return this.UpTo(migrations[len(migrations)-1].Version)
+}
+
+func (this *Migrator) downTo(version int64, limit int) error {
+	return this.run(func() error {
+		applied, err := this.GetApplied()
+		if err != nil {
+			return err
+		}
+		err = this.verify(applied)
+		if err != nil {
+			return err
+		}
+		count := 0
+		for i := len(applied) - 1; i >= 0; i-- {
+			if applied[i].Version <= version ||
+				limit > 0 && count >= limit {
+				break
+			}
+			err = this.applyDown(this.findMigration(applied[i].Version))
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+}
+
+// DownTo revert applied migrations with version greater than specified.
+func (this *Migrator) DownTo(version int64) error {
+	return this.downTo(version, 0)
+}
+
+// Down revert last applied migration.
+func (this *Migrator) Down() error {
+	return this.downTo(math.MinInt64, 1)
+}
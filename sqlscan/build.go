@@ -0,0 +1,377 @@
+package sqlscan
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+	"github.com/d2r2/sqlg/sqlinsert"
+	"github.com/d2r2/sqlg/sqlupdate"
+)
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+func isAutoinc(field *sqldb.FieldDef) bool {
+	return field.Data.Type.In(sqldef.DT_AUTOINC_INT | sqldef.DT_AUTOINC_INT_BIG)
+}
+
+// Get struct values from struct, pointer to struct
+// or slice of them.
+func getStructItems(src interface{}) ([]reflect.Value, *structInfo, error) {
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	var items []reflect.Value
+	var t reflect.Type
+	switch v.Kind() {
+	case reflect.Struct:
+		items = append(items, v)
+		t = v.Type()
+	case reflect.Slice, reflect.Array:
+		t = v.Type().Elem()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			if item.Kind() == reflect.Ptr {
+				if item.IsNil() {
+					return nil, nil, e("Item %d of %v is nil", i, v.Type())
+				}
+				item = item.Elem()
+			}
+			items = append(items, item)
+		}
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil, e("Can't get values from %T, since struct "+
+			"or slice of structs expected", src)
+	}
+	if len(items) == 0 {
+		return nil, nil, e("No items in %v", v.Type())
+	}
+	return items, getStructInfo(t), nil
+}
+
+// Match table fields to struct fields;
+// nil item correspond to table field missing in struct.
+func (this *Mapper) getTableMap(table *sqldb.TableDef,
+	info *structInfo) ([]*structField, error) {
+	m := make([]*structField, len(table.Fields.Items))
+	used := make(map[*structField]bool)
+	for i, field := range table.Fields.Items {
+		sf := info.findField(field.Name)
+		if sf != nil {
+			m[i] = sf
+			used[sf] = true
+		}
+	}
+	if !this.IgnoreMissing {
+		for _, sf := range info.Fields {
+			if !used[sf] {
+				return nil, e("Field %s of %v doesn't match any column "+
+					"of table \"%s\"", sf.Name, info.Type, table.Name)
+			}
+		}
+	}
+	return m, nil
+}
+
+// Convert struct field to value, which could be passed to sqlexp.
+func getValue(v reflect.Value) (interface{}, error) {
+	if v.Type().Implements(valuerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, nil
+		}
+		return v.Interface().(driver.Valuer).Value()
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		return getValue(v.Elem())
+	}
+	return v.Interface(), nil
+}
+
+// Find struct field value; false returned, when
+// it's inside of nil embedded struct pointer.
+func findFieldValue(item reflect.Value, sf *structField) (reflect.Value, bool) {
+	v := item
+	for i, j := range sf.Index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(j)
+	}
+	return v, true
+}
+
+func getFieldValue(item reflect.Value, sf *structField) (interface{}, error) {
+	v, ok := findFieldValue(item, sf)
+	if !ok {
+		return nil, nil
+	}
+	return getValue(v)
+}
+
+func isZeroField(item reflect.Value, sf *structField) bool {
+	v, ok := findFieldValue(item, sf)
+	return !ok || v.IsZero()
+}
+
+// InsertValues build insert of struct, or slice of structs as multiple
+// rows, into table. Autoincrement fields are skipped. With OmitZero,
+// field is skipped when it has zero value in all items.
+func (this *Mapper) InsertValues(table *sqldb.TableDef,
+	src interface{}) (sqlinsert.Values, error) {
+	items, info, err := getStructItems(src)
+	if err != nil {
+		return nil, err
+	}
+	m, err := this.getTableMap(table, info)
+	if err != nil {
+		return nil, err
+	}
+	ef := sqlexp.Factory()
+	var fields []*sqlexp.TokenField
+	var sfs []*structField
+	for i, field := range table.Fields.Items {
+		sf := m[i]
+		if sf == nil || isAutoinc(field) {
+			continue
+		}
+		if this.OmitZero {
+			zero := true
+			for _, item := range items {
+				if !isZeroField(item, sf) {
+					zero = false
+					break
+				}
+			}
+			if zero {
+				continue
+			}
+		}
+		fields = append(fields, ef.Field(table, field.Name))
+		sfs = append(sfs, sf)
+	}
+	if len(fields) == 0 {
+		return nil, e("No fields of %v to insert into table \"%s\"",
+			info.Type, table.Name)
+	}
+	ins := sqlinsert.NewInsert(table, fields...)
+	var values sqlinsert.Values
+	for _, item := range items {
+		exprs := make([]sqlexp.Expr, len(sfs))
+		for i, sf := range sfs {
+			value, err := getFieldValue(item, sf)
+			if err != nil {
+				return nil, err
+			}
+			exprs[i] = ef.Value(value)
+		}
+		if values == nil {
+			values = ins.Values(exprs[0], exprs[1:]...)
+		} else {
+			values = values.Values(exprs[0], exprs[1:]...)
+		}
+	}
+	return values, nil
+}
+
+func getAutoincField(table *sqldb.TableDef) *sqldb.FieldDef {
+	for _, field := range table.Fields.Items {
+		if isAutoinc(field) {
+			return field
+		}
+	}
+	return nil
+}
+
+// Insert build insert same as InsertValues, and when single struct
+// is inserted into table with autoincrement field, add returning of
+// generated key. In that case statement batch end with query (MySQL
+// and SQLite add "select last_insert_id()" statement), so it must
+// be run with ExecQueryRow or Query rather than Exec. Returning isn't
+// added for slice, since MySQL and SQLite return single key only;
+// use InsertContext to get keys of all inserted rows.
+func (this *Mapper) Insert(table *sqldb.TableDef,
+	src interface{}) (sqlcore.SqlReady, error) {
+	items, _, err := getStructItems(src)
+	if err != nil {
+		return nil, err
+	}
+	values, err := this.InsertValues(table, src)
+	if err != nil {
+		return nil, err
+	}
+	field := getAutoincField(table)
+	if field != nil && len(items) == 1 {
+		ef := sqlexp.Factory()
+		return values.Returning(ef.Field(table, field.Name)), nil
+	}
+	return values, nil
+}
+
+// InsertContext insert struct or slice of structs into table
+// and write generated key back to autoincrement field of struct,
+// so src must be pointer to struct or slice of structs (or
+// pointers). Each item is inserted with separate statement.
+func (this *Mapper) InsertContext(ctx context.Context, db sqlcore.Querier,
+	format *sqlcore.Format, table *sqldb.TableDef, src interface{}) error {
+	items, info, err := getStructItems(src)
+	if err != nil {
+		return err
+	}
+	var key *structField
+	field := getAutoincField(table)
+	if field != nil {
+		key = info.findField(field.Name)
+	}
+	for i, item := range items {
+		s, err := this.Insert(table, item.Interface())
+		if err != nil {
+			return err
+		}
+		batch, err := s.GetSql(format)
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			_, err = batch.ExecContext(ctx, db)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		v, ok := findFieldValue(item, key)
+		if !ok || !v.CanSet() {
+			return e("Can't write generated key to field %s of %v, "+
+				"pass pointer to struct or slice", key.Name, info.Type)
+		}
+		row, err := batch.QueryRowContext(ctx, db)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if err := row.Scan(&value); err != nil {
+			return e("Can't read generated key of item %d: %v", i, err)
+		}
+		if err := assign(v, value); err != nil {
+			return e("Can't assign generated key to field %s of %v: %v",
+				key.Name, info.Type, err)
+		}
+	}
+	return nil
+}
+
+func (this *Mapper) buildUpdate(table *sqldb.TableDef, m []*structField,
+	info *structInfo, item reflect.Value) (sqlupdate.Where, error) {
+	ef := sqlexp.Factory()
+	keys := table.GetOrAdvicePrimaryKey().Items
+	if len(keys) == 0 {
+		return nil, e("Table \"%s\" has no primary key to update by", table.Name)
+	}
+	var assigns []*sqlexp.TokenFieldAssign
+	var cond sqlexp.Expr
+	for i, field := range table.Fields.Items {
+		sf := m[i]
+		isKey := false
+		for _, key := range keys {
+			if key == field {
+				isKey = true
+				break
+			}
+		}
+		if isKey {
+			if sf == nil {
+				return nil, e("Primary key field \"%s\" of table \"%s\" "+
+					"doesn't match any field of %v", field.Name, table.Name, info.Type)
+			}
+			value, err := getFieldValue(item, sf)
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				return nil, e("Primary key field %s of %v is nil",
+					sf.Name, info.Type)
+			}
+			expr := ef.Equal(ef.Field(table, field.Name), ef.Value(value))
+			if cond == nil {
+				cond = expr
+			} else {
+				cond = ef.And(cond, expr)
+			}
+			continue
+		}
+		if sf == nil || isAutoinc(field) {
+			continue
+		}
+		if this.OmitZero && isZeroField(item, sf) {
+			continue
+		}
+		value, err := getFieldValue(item, sf)
+		if err != nil {
+			return nil, err
+		}
+		assigns = append(assigns, ef.Assign(ef.Field(table, field.Name),
+			ef.Value(value)))
+	}
+	if len(assigns) == 0 {
+		return nil, e("No fields of %v to update in table \"%s\"",
+			info.Type, table.Name)
+	}
+	upd := sqlupdate.NewUpdate(table, assigns...).Where(cond)
+	return upd, nil
+}
+
+// Update build update of table row, identified by primary key
+// taken from struct. Primary key and autoincrement fields are
+// not updated. With OmitZero, fields with zero values are skipped.
+func (this *Mapper) Update(table *sqldb.TableDef,
+	src interface{}) (sqlupdate.Where, error) {
+	items, info, err := getStructItems(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 1 {
+		return nil, e("Can't update multiple items of %T "+
+			"with one statement, use UpdateAll", src)
+	}
+	m, err := this.getTableMap(table, info)
+	if err != nil {
+		return nil, err
+	}
+	return this.buildUpdate(table, m, info, items[0])
+}
+
+// UpdateAll build update statement for each item of struct slice.
+func (this *Mapper) UpdateAll(table *sqldb.TableDef,
+	src interface{}) ([]sqlupdate.Where, error) {
+	items, info, err := getStructItems(src)
+	if err != nil {
+		return nil, err
+	}
+	m, err := this.getTableMap(table, info)
+	if err != nil {
+		return nil, err
+	}
+	var updates []sqlupdate.Where
+	for _, item := range items {
+		upd, err := this.buildUpdate(table, m, info, item)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, upd)
+	}
+	return updates, nil
+}
@@ -0,0 +1,264 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+)
+
+// Time formats, which SQLite keep datetime as text with;
+// first one is used by sqlexp to write time values.
+var timeFormats = []string{
+	"2006-01-02T15:04:05.000",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// Struct type, which is scanned as single value, rather than
+// mapped field by field, when embedded.
+func isValueType(t reflect.Type) bool {
+	return t == timeType || reflect.PtrTo(t).Implements(scannerType)
+}
+
+func parseTime(src interface{}) (time.Time, error) {
+	switch v := src.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		return parseTime(string(v))
+	case string:
+		s := strings.TrimSpace(strings.TrimSuffix(v, "Z"))
+		for _, layout := range timeFormats {
+			if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, e("Can't parse time \"%s\"", v)
+	}
+	return time.Time{}, e("Can't convert %T to time", src)
+}
+
+// Time of day is kept by sqlexp as time.Duration. Database return it
+// either as time.Time with zero date (T-SQL) or as text "15:04:05.999".
+func parseDuration(src interface{}) (time.Duration, error) {
+	switch v := src.(type) {
+	case time.Time:
+		day := time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, v.Location())
+		return v.Sub(day), nil
+	case []byte:
+		return parseDuration(string(v))
+	case string:
+		t, err := time.Parse("15:04:05.999999999", strings.TrimSpace(v))
+		if err != nil {
+			return 0, e("Can't parse time of day \"%s\"", v)
+		}
+		return parseDuration(t)
+	case int64:
+		return time.Duration(v), nil
+	}
+	return 0, e("Can't convert %T to time of day", src)
+}
+
+// Boolean could be returned as bool (T-SQL bit, PostgreSQL),
+// integer (SQLite, MySQL tinyint(1)) or text.
+func parseBool(src interface{}) (bool, error) {
+	switch v := src.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	case []byte:
+		return parseBool(string(v))
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, e("Can't parse boolean \"%s\"", v)
+		}
+		return b, nil
+	}
+	return false, e("Can't convert %T to boolean", src)
+}
+
+func getText(src interface{}) (string, bool) {
+	switch v := src.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
+
+// Assign value returned by driver to struct field.
+func assign(dst reflect.Value, src interface{}) error {
+	if dst.CanAddr() && dst.Addr().Type().Implements(scannerType) {
+		if src != nil && dst.Type() == nullTimeType {
+			t, err := parseTime(src)
+			if err != nil {
+				return err
+			}
+			src = t
+		}
+		return dst.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+	if src == nil {
+		switch dst.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		return e("Value is NULL, but %v is not nullable "+
+			"(use pointer or sql.Null* type)", dst.Type())
+	}
+	if dst.Kind() == reflect.Ptr {
+		v := reflect.New(dst.Type().Elem())
+		if err := assign(v.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(v)
+		return nil
+	}
+	switch dst.Type() {
+	case timeType:
+		t, err := parseTime(src)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := parseDuration(src)
+		if err != nil {
+			return err
+		}
+		dst.SetInt(int64(d))
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Interface:
+		dst.Set(reflect.ValueOf(src))
+		return nil
+	case reflect.Bool:
+		b, err := parseBool(src)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+		return nil
+	case reflect.String:
+		switch v := src.(type) {
+		case string:
+			dst.SetString(v)
+		case []byte:
+			dst.SetString(string(v))
+		case time.Time:
+			dst.SetString(v.Format(time.RFC3339Nano))
+		default:
+			dst.SetString(f("%v", v))
+		}
+		return nil
+	case reflect.Slice:
+		if b, ok := src.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch v := src.(type) {
+		case int64:
+			i = v
+		case bool:
+			if v {
+				i = 1
+			}
+		case float64:
+			i = int64(v)
+			if float64(i) != v {
+				return e("Can't convert %v to integer without loss", v)
+			}
+		default:
+			s, ok := getText(src)
+			if !ok {
+				return e("Can't convert %T to %v", src, dst.Type())
+			}
+			var err error
+			i, err = strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				return e("Can't parse integer \"%s\"", s)
+			}
+		}
+		if dst.OverflowInt(i) {
+			return e("Value %d overflow %v", i, dst.Type())
+		}
+		dst.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch v := src.(type) {
+		case int64:
+			if v < 0 {
+				return e("Can't convert negative value %d to %v", v, dst.Type())
+			}
+			u = uint64(v)
+		case bool:
+			if v {
+				u = 1
+			}
+		default:
+			s, ok := getText(src)
+			if !ok {
+				return e("Can't convert %T to %v", src, dst.Type())
+			}
+			var err error
+			u, err = strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				return e("Can't parse unsigned integer \"%s\"", s)
+			}
+		}
+		if dst.OverflowUint(u) {
+			return e("Value %d overflow %v", u, dst.Type())
+		}
+		dst.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var fl float64
+		switch v := src.(type) {
+		case float64:
+			fl = v
+		case int64:
+			fl = float64(v)
+		default:
+			s, ok := getText(src)
+			if !ok {
+				return e("Can't convert %T to %v", src, dst.Type())
+			}
+			var err error
+			fl, err = strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return e("Can't parse number \"%s\"", s)
+			}
+		}
+		dst.SetFloat(fl)
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+	return e("Can't convert %T to %v", src, dst.Type())
+}
@@ -0,0 +1,107 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	cases := []struct {
+		src  interface{}
+		want time.Time
+		err  bool
+	}{
+		// SQLite keep datetime as text in different formats
+		{"2024-05-06T07:08:09.000", want, false},
+		{"2024-05-06 07:08:09", want, false},
+		{"2024-05-06T07:08:09Z", want, false},
+		{[]byte("2024-05-06 07:08:09"), want, false},
+		{"2024-05-06 07:08:09.5", want.Add(500 * time.Millisecond), false},
+		{"2024-05-06 10:08:09+03:00", want, false},
+		{"2024-05-06", time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), false},
+		{want, want, false},
+		{"yesterday", time.Time{}, true},
+		{int64(1), time.Time{}, true},
+	}
+	for _, c := range cases {
+		got, err := parseTime(c.src)
+		if c.err {
+			if err == nil {
+				t.Errorf("parseTime(%#v): error expected", c.src)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTime(%#v): %v", c.src, err)
+			continue
+		}
+		if !got.Equal(c.want) {
+			t.Errorf("parseTime(%#v) = %v; want %v", c.src, got, c.want)
+		}
+	}
+}
+
+func TestAssign(t *testing.T) {
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	i := 5
+	cases := []struct {
+		name string
+		dst  interface{}
+		src  interface{}
+		want interface{}
+		err  bool
+	}{
+		{"int from int64", new(int), int64(5), 5, false},
+		{"int from text", new(int), []byte("5"), 5, false},
+		{"int8 overflow", new(int8), int64(300), nil, true},
+		{"int from float with loss", new(int), 1.5, nil, true},
+		{"uint from negative", new(uint), int64(-1), nil, true},
+		{"float from int64", new(float64), int64(2), 2.0, false},
+		{"float from text", new(float32), "1.5", float32(1.5), false},
+		{"string from bytes", new(string), []byte("abc"), "abc", false},
+		{"string from int64", new(string), int64(7), "7", false},
+		// T-SQL bit and PostgreSQL boolean
+		{"bool from bool", new(bool), true, true, false},
+		// SQLite and MySQL tinyint(1)
+		{"bool from int64", new(bool), int64(1), true, false},
+		{"bool from text", new(bool), []byte("0"), false, false},
+		{"bool from bad text", new(bool), "maybe", nil, true},
+		{"int from bool", new(int), true, 1, false},
+		{"time from text", new(time.Time), "2024-05-06 07:08:09", tm, false},
+		{"duration from text", new(time.Duration), "01:02:03",
+			time.Hour + 2*time.Minute + 3*time.Second, false},
+		{"duration from time", new(time.Duration),
+			time.Date(1, 1, 1, 1, 2, 3, 0, time.UTC),
+			time.Hour + 2*time.Minute + 3*time.Second, false},
+		{"pointer from value", new(*int), int64(5), &i, false},
+		{"pointer from null", new(*int), nil, (*int)(nil), false},
+		{"int from null", new(int), nil, nil, true},
+		{"null string from null", new(sql.NullString), nil,
+			sql.NullString{}, false},
+		{"null time from text", new(sql.NullTime), "2024-05-06 07:08:09",
+			sql.NullTime{Time: tm, Valid: true}, false},
+		{"bytes from bytes", new([]byte), []byte("ab"), []byte("ab"), false},
+		{"interface from int64", new(interface{}), int64(5), int64(5), false},
+		{"int from time", new(int), tm, nil, true},
+	}
+	for _, c := range cases {
+		dst := reflect.ValueOf(c.dst).Elem()
+		err := assign(dst, c.src)
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: error expected", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(dst.Interface(), c.want) {
+			t.Errorf("%s: got %#v; want %#v", c.name, dst.Interface(), c.want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package sqlscan
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/d2r2/sqlg/sqlcore"
+)
+
+// Struct tag to specify column name for the field,
+// or "-" to exclude the field from mapping:
+//
+//	type Customer struct {
+//		ID      int        `sql:"id"`
+//		Name    *string    `sql:"cust_name"`
+//		Created time.Time
+//		Note    string     `sql:"-"`
+//	}
+//
+// Field without tag matched to column ignoring case and underscores,
+// so field CustID match column "cust_id". Column name is the one
+// returned by database, so alias specified by ef.FieldAlias is used.
+const TagName = "sql"
+
+type structField struct {
+	Name   string
+	Column string
+	Index  []int
+}
+
+// Fields of struct, which take part in mapping;
+// fields of embedded structs are included.
+type structInfo struct {
+	Type   reflect.Type
+	Fields []*structField
+}
+
+var structs sync.Map
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+func collectFields(t reflect.Type, index []int, info *structInfo) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+		path := append(append([]int{}, index...), i)
+		if field.Anonymous && tag == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !isValueType(ft) {
+				collectFields(ft, path, info)
+				continue
+			}
+		}
+		// skip unexported field
+		if field.PkgPath != "" {
+			continue
+		}
+		item := &structField{Name: field.Name, Column: tag, Index: path}
+		info.Fields = append(info.Fields, item)
+	}
+}
+
+func getStructInfo(t reflect.Type) *structInfo {
+	if v, ok := structs.Load(t); ok {
+		return v.(*structInfo)
+	}
+	info := &structInfo{Type: t}
+	collectFields(t, nil, info)
+	v, _ := structs.LoadOrStore(t, info)
+	return v.(*structInfo)
+}
+
+func (this *structInfo) findField(column string) *structField {
+	// tagged field has priority
+	for _, field := range this.Fields {
+		if field.Column != "" && strings.EqualFold(field.Column, column) {
+			return field
+		}
+	}
+	name := normalizeName(column)
+	for _, field := range this.Fields {
+		if field.Column == "" && normalizeName(field.Name) == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// Mapper scan query result rows into structs and build insert
+// and update statements from structs. By default every column
+// must be mapped to struct field and every field must be present
+// in columns, otherwise error is returned.
+type Mapper struct {
+	// skip columns, which don't match any field
+	IgnoreUnmapped bool
+	// leave fields, which don't match any column, untouched
+	IgnoreMissing bool
+	// don't write fields with zero values in insert and update,
+	// so database default is used or current value is kept
+	OmitZero bool
+}
+
+func NewMapper() *Mapper {
+	r := &Mapper{}
+	return r
+}
+
+// Map of result columns to struct fields;
+// nil item correspond to unmapped column.
+type columnMap struct {
+	Columns []string
+	Fields  []*structField
+}
+
+func (this *Mapper) getColumnMap(info *structInfo,
+	columns []string) (*columnMap, error) {
+	m := &columnMap{Columns: columns, Fields: make([]*structField, len(columns))}
+	used := make(map[*structField]string)
+	for i, column := range columns {
+		field := info.findField(column)
+		if field == nil {
+			if !this.IgnoreUnmapped {
+				return nil, e("Column \"%s\" doesn't match any field of %v",
+					column, info.Type)
+			}
+			continue
+		}
+		if column2, ok := used[field]; ok {
+			return nil, e("Columns \"%s\" and \"%s\" both match field %s of %v",
+				column2, column, field.Name, info.Type)
+		}
+		used[field] = column
+		m.Fields[i] = field
+	}
+	if !this.IgnoreMissing {
+		for _, field := range info.Fields {
+			if _, ok := used[field]; !ok {
+				return nil, e("Field %s of %v is not found "+
+					"in query result columns %v", field.Name, info.Type, columns)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (this *Mapper) scan(rows *sql.Rows, m *columnMap, v reflect.Value) error {
+	values := make([]interface{}, len(m.Columns))
+	ptrs := make([]interface{}, len(m.Columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return err
+	}
+	for i, field := range m.Fields {
+		if field == nil {
+			continue
+		}
+		fv, err := getFieldByIndex(v, field.Index)
+		if err != nil {
+			return err
+		}
+		if err := assign(fv, values[i]); err != nil {
+			return e("Can't assign column \"%s\" to field %s of %v: %v",
+				m.Columns[i], field.Name, v.Type(), err)
+		}
+	}
+	return nil
+}
+
+// Find field by index path, allocating nil embedded struct pointers.
+func getFieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, j := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, e("Can't set embedded pointer of %v", v.Type())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(j)
+	}
+	return v, nil
+}
+
+func getStructType(t reflect.Type) (reflect.Type, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, e("Can't scan into %v, since struct expected", t)
+	}
+	return t, nil
+}
+
+// ScanRow scan current row into struct, which dest point to.
+func (this *Mapper) ScanRow(rows *sql.Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return e("Can't scan into %T, since pointer to struct expected", dest)
+	}
+	t, err := getStructType(v.Type().Elem())
+	if err != nil {
+		return err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	m, err := this.getColumnMap(getStructInfo(t), columns)
+	if err != nil {
+		return err
+	}
+	return this.scan(rows, m, v.Elem())
+}
+
+// ScanOne scan first row into struct, which dest point to, and close rows.
+// Return sql.ErrNoRows if there is no rows.
+func (this *Mapper) ScanOne(rows *sql.Rows, dest interface{}) error {
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := this.ScanRow(rows, dest); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// ScanAll scan all rows into slice of structs (or pointers
+// to structs), which dest point to, and close rows.
+func (this *Mapper) ScanAll(rows *sql.Rows, dest interface{}) error {
+	defer rows.Close()
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return e("Can't scan into %T, since pointer to slice expected", dest)
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	t, err := getStructType(elemType)
+	if err != nil {
+		return err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	m, err := this.getColumnMap(getStructInfo(t), columns)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		item := reflect.New(t)
+		if err := this.scan(rows, m, item.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, item))
+		} else {
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// QueryOne run query batch and scan first row into struct.
+func (this *Mapper) QueryOne(ctx context.Context, db sqlcore.Querier,
+	batch *sqlcore.StatementBatch, dest interface{}) error {
+	rows, err := batch.QueryContext(ctx, db)
+	if err != nil {
+		return err
+	}
+	return this.ScanOne(rows, dest)
+}
+
+// QueryAll run query batch and scan all rows into slice of structs.
+func (this *Mapper) QueryAll(ctx context.Context, db sqlcore.Querier,
+	batch *sqlcore.StatementBatch, dest interface{}) error {
+	rows, err := batch.QueryContext(ctx, db)
+	if err != nil {
+		return err
+	}
+	return this.ScanAll(rows, dest)
+}
+
+func ScanRow(rows *sql.Rows, dest interface{}) error {
+	return NewMapper().ScanRow(rows, dest)
+}
+
+func ScanOne(rows *sql.Rows, dest interface{}) error {
+	return NewMapper().ScanOne(rows, dest)
+}
+
+func ScanAll(rows *sql.Rows, dest interface{}) error {
+	return NewMapper().ScanAll(rows, dest)
+}
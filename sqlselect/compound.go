@@ -0,0 +1,245 @@
+package sqlselect
+
+// Generate sql statement according to the syntax:
+//      select ... from ...
+//      union [all] | intersect | except
+//      select ... from ...
+//      [order by expr1, expr2, ...]
+//      [limit ... offset ...]
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+type Compound interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+	sqlcore.Query
+	Union(sel sqlcore.SqlReady) Compound
+	UnionAll(sel sqlcore.SqlReady) Compound
+	Intersect(sel sqlcore.SqlReady) Compound
+	Except(sel sqlcore.SqlReady) Compound
+	OrderBy(first sqlexp.Expr, rest ...sqlexp.Expr) OrderBy
+	Limit(count int) Limit
+	Offset(skip int) Limit
+}
+
+type compound struct {
+	// data
+	Left  sqlcore.SqlReady
+	Right sqlcore.SqlReady
+	Kind  sqlcore.CompoundKind
+}
+
+func newCompound(left, right sqlcore.SqlReady,
+	kind sqlcore.CompoundKind) Compound {
+	sc := &compound{Left: left, Right: right, Kind: kind}
+	return sc
+}
+
+func NewUnion(left, right sqlcore.SqlReady) Compound {
+	return newCompound(left, right, sqlcore.CK_UNION)
+}
+
+func NewUnionAll(left, right sqlcore.SqlReady) Compound {
+	return newCompound(left, right, sqlcore.CK_UNION_ALL)
+}
+
+func NewIntersect(left, right sqlcore.SqlReady) Compound {
+	return newCompound(left, right, sqlcore.CK_INTERSECT)
+}
+
+func NewExcept(left, right sqlcore.SqlReady) Compound {
+	return newCompound(left, right, sqlcore.CK_EXCEPT)
+}
+
+func (this *compound) Union(sel sqlcore.SqlReady) Compound {
+	return newCompound(this, sel, sqlcore.CK_UNION)
+}
+
+func (this *compound) UnionAll(sel sqlcore.SqlReady) Compound {
+	return newCompound(this, sel, sqlcore.CK_UNION_ALL)
+}
+
+func (this *compound) Intersect(sel sqlcore.SqlReady) Compound {
+	return newCompound(this, sel, sqlcore.CK_INTERSECT)
+}
+
+func (this *compound) Except(sel sqlcore.SqlReady) Compound {
+	return newCompound(this, sel, sqlcore.CK_EXCEPT)
+}
+
+// Fields in "order by" section should refer to compound statement
+// itself, and will be rendered as column names without qualifier.
+func (this *compound) OrderBy(first sqlexp.Expr, rest ...sqlexp.Expr) OrderBy {
+	fields := []sqlexp.Expr{first}
+	fields = append(fields, rest...)
+	so := &orderBy{Compound: this, Fields: fields}
+	return so
+}
+
+func (this *compound) Limit(count int) Limit {
+	sl := &limit{Compound: this, Count: &count}
+	return sl
+}
+
+func (this *compound) Offset(skip int) Limit {
+	sl := &limit{Compound: this, Skip: &skip}
+	return sl
+}
+
+// Column names of the compound statement
+// are taken from the first statement.
+func (this *compound) getLeftQuery() (sqlcore.Query, error) {
+	query, ok := this.Left.(sqlcore.Query)
+	if !ok {
+		return nil, e("Can't get columns of compound statement, " +
+			"since first statement is not a query")
+	}
+	return query, nil
+}
+
+func (this *compound) IsTableBased() (bool, sqlcore.Table) {
+	return false, nil
+}
+
+func (this *compound) GetColumnCount() (int, error) {
+	query, err := this.getLeftQuery()
+	if err != nil {
+		return 0, err
+	}
+	return query.GetColumnCount()
+}
+
+func (this *compound) ColumnIsAmbiguous(name string) (bool, error) {
+	query, err := this.getLeftQuery()
+	if err != nil {
+		return false, err
+	}
+	return query.ColumnIsAmbiguous(name)
+}
+
+func (this *compound) ColumnExists(name string) (bool, error) {
+	query, err := this.getLeftQuery()
+	if err != nil {
+		return false, err
+	}
+	return query.ColumnExists(name)
+}
+
+func (this *compound) validate(format *sqlcore.Format) error {
+	if this.Left == nil || this.Right == nil {
+		return e("Statement specified in compound statement is nil")
+	}
+	if format.ColumnNameAndCountValidationIsOn() {
+		left, leftOk := this.Left.(sqlcore.Query)
+		right, rightOk := this.Right.(sqlcore.Query)
+		if leftOk && rightOk {
+			c1, err := left.GetColumnCount()
+			if err != nil {
+				return err
+			}
+			c2, err := right.GetColumnCount()
+			if err != nil {
+				return err
+			}
+			if c1 != c2 {
+				return e("Column count doesn't match in "+
+					"compound statement: %d <> %d", c1, c2)
+			}
+		}
+	}
+	return nil
+}
+
+// Statement with own "order by", "limit" sections or compound one
+// should be isolated from the rest of compound statement.
+func (this *compound) needIsolation(sel sqlcore.SqlReady) bool {
+	part, ok := sel.(sqlcore.SqlPart)
+	return ok && part.GetPartKind().In(sqlcore.SPK_SELECT_ORDER_BY|
+		sqlcore.SPK_SELECT_LIMIT|sqlcore.SPK_SELECT_COMPOUND)
+}
+
+func (this *compound) buildOperandSql(maker *maker,
+	stat *sqlcore.Statement, sel sqlcore.SqlReady) error {
+	isolate := this.needIsolation(sel)
+	if isolate {
+		stat.WriteString(maker.Format.GetLeadingSpace())
+		// SQLite doesn't allow statement in brackets,
+		// so use subquery in "from" section instead
+		if maker.Format.Dialect == sqldef.DI_SQLITE {
+			stat.WriteString("select * from ")
+		}
+		stat.WriteString("(")
+		stat.WriteString(maker.Format.SectionDivider)
+		maker.Format.IncIndentLevel()
+	}
+	batch, err := sel.GetSql(maker.Format)
+	if isolate {
+		maker.Format.DecIndentLevel()
+	}
+	if err != nil {
+		return err
+	}
+	if len(batch.Items) > 1 {
+		return e("Can't use multiple sql statments "+
+			"in compound statement: %v", batch)
+	}
+	stat.AppendStatPart(batch.Items[0])
+	if isolate {
+		stat.WriteString(maker.Format.SectionDivider)
+		stat.WriteString(maker.Format.GetLeadingSpace())
+		stat.WriteString(")")
+	}
+	return nil
+}
+
+func (this *compound) buildCompoundSectionSql(maker *maker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	err := this.validate(maker.Format)
+	if err != nil {
+		return err
+	}
+	ck := map[sqlcore.CompoundKind]string{
+		sqlcore.CK_UNION:     "union",
+		sqlcore.CK_UNION_ALL: "union all",
+		sqlcore.CK_INTERSECT: "intersect",
+		sqlcore.CK_EXCEPT:    "except"}
+	err = this.buildOperandSql(maker, stat, this.Left)
+	if err != nil {
+		return err
+	}
+	stat.WriteString(maker.Format.SectionDivider)
+	stat.WriteString(maker.Format.GetLeadingSpace())
+	stat.WriteString(ck[this.Kind])
+	stat.WriteString(maker.Format.SectionDivider)
+	err = this.buildOperandSql(maker, stat, this.Right)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *compound) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &maker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, nil
+}
+
+func (this *compound) Validate(format *sqlcore.Format) error {
+	_, err := this.GetSql(format)
+	return err
+}
+
+func (this *compound) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_SELECT_COMPOUND
+}
+
+func (this *compound) GetParent() sqlcore.SqlPart {
+	return nil
+}
@@ -2,6 +2,7 @@ package sqlselect
 
 import (
 	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
 	"github.com/d2r2/sqlg/sqlexp"
 )
 
@@ -11,8 +12,13 @@ type From interface {
 	InnerJoin(query sqlcore.Query, joinCond sqlexp.Expr) From
 	LeftJoin(query sqlcore.Query, joinCond sqlexp.Expr) From
 	RightJoin(query sqlcore.Query, joinCond sqlexp.Expr) From
+	FullJoin(query sqlcore.Query, joinCond sqlexp.Expr) From
+	CrossJoin(query sqlcore.Query) From
 	Where(cond sqlexp.Expr) Where
+	GroupBy(firstExpr sqlexp.Expr, restExprs ...sqlexp.Expr) GroupBy
 	OrderBy(firstExpr sqlexp.Expr, restExprs ...sqlexp.Expr) OrderBy
+	Limit(count int) Limit
+	Offset(skip int) Limit
 }
 
 type from struct {
@@ -44,11 +50,34 @@ func (this *from) RightJoin(query sqlcore.Query, joinCond sqlexp.Expr) From {
 	return sf
 }
 
+// Full outer join. MySQL doesn't support it, so error is returned
+// for the dialect. SQLite supports it starting from version 3.39 only,
+// older versions fail with syntax error when statement is executed.
+func (this *from) FullJoin(query sqlcore.Query, joinCond sqlexp.Expr) From {
+	sf := &from{From: this, DataSource: query,
+		JoinKind: sqlcore.JK_FULL, JoinCond: joinCond}
+	return sf
+}
+
+// Cross join doesn't have join condition.
+func (this *from) CrossJoin(query sqlcore.Query) From {
+	sf := &from{From: this, DataSource: query,
+		JoinKind: sqlcore.JK_CROSS}
+	return sf
+}
+
 func (this *from) Where(cond sqlexp.Expr) Where {
 	sw := &where{From: this, Cond: cond}
 	return sw
 }
 
+func (this *from) GroupBy(firstExpr sqlexp.Expr, restExprs ...sqlexp.Expr) GroupBy {
+	fields := []sqlexp.Expr{firstExpr}
+	fields = append(fields, restExprs...)
+	sg := &groupBy{From: this, Fields: fields}
+	return sg
+}
+
 func (this *from) OrderBy(firstExpr sqlexp.Expr, restExprs ...sqlexp.Expr) OrderBy {
 	fields := []sqlexp.Expr{firstExpr}
 	fields = append(fields, restExprs...)
@@ -56,6 +85,16 @@ func (this *from) OrderBy(firstExpr sqlexp.Expr, restExprs ...sqlexp.Expr) Order
 	return so
 }
 
+func (this *from) Limit(count int) Limit {
+	sl := &limit{From: this, Count: &count}
+	return sl
+}
+
+func (this *from) Offset(skip int) Limit {
+	sl := &limit{From: this, Skip: &skip}
+	return sl
+}
+
 func (this *from) IsTableBased() (bool, sqlcore.Table) {
 	return false, nil
 }
@@ -77,7 +116,7 @@ func (this *from) ColumnExists(name string) (bool, error) {
 
 func (this *from) buildFromSectionSql(maker *maker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
-	if this.JoinCond == nil {
+	if this.From == nil {
 		stat2, err := maker.Format.FormatDataSourceRef(this.DataSource)
 		if err != nil {
 			return err
@@ -92,7 +131,21 @@ func (this *from) buildFromSectionSql(maker *maker,
 		jk := map[sqlcore.JoinKind]string{
 			sqlcore.JK_INNER: "inner",
 			sqlcore.JK_LEFT:  "left",
-			sqlcore.JK_RIGHT: "right"}
+			sqlcore.JK_RIGHT: "right",
+			sqlcore.JK_FULL:  "full",
+			sqlcore.JK_CROSS: "cross"}
+		// MySQL doesn't support full outer join at all,
+		// SQLite supports it starting from version 3.39,
+		// which can't be detected here (see FullJoin)
+		if this.JoinKind == sqlcore.JK_FULL &&
+			maker.Format.Dialect == sqldef.DI_MYSQL {
+			return e("%v dialect doesn't support \"full join\", "+
+				"use union of \"left join\" and \"right join\" instead",
+				maker.Format.Dialect)
+		}
+		if this.JoinKind != sqlcore.JK_CROSS && this.JoinCond == nil {
+			return e("Condition of \"%s join\" is nil", jk[this.JoinKind])
+		}
 		maker.IncScopeVisIndex()
 		context := maker.GetExprBuildContext(
 			sqlcore.SPK_SELECT_FROM_OR_JOIN, sqlcore.SSPK_EXPR1, stack, maker.Format)
@@ -103,12 +156,16 @@ func (this *from) buildFromSectionSql(maker *maker,
 		stat.WriteString(maker.Format.SectionDivider)
 		stat.WriteString(maker.Format.GetLeadingSpace())
 		stat.WriteString(f("%s join ", jk[this.JoinKind]))
-		stat.AppendStatPartsFormat("%s on ", stat2)
-		stat3, err := this.JoinCond.GetSql(context)
-		if err != nil {
-			return err
+		if this.JoinKind == sqlcore.JK_CROSS {
+			stat.AppendStatPart(stat2)
+		} else {
+			stat.AppendStatPartsFormat("%s on ", stat2)
+			stat3, err := this.JoinCond.GetSql(context)
+			if err != nil {
+				return err
+			}
+			stat.AppendStatPart(stat3)
 		}
-		stat.AppendStatPart(stat3)
 	}
 	return nil
 }
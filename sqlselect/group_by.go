@@ -8,7 +8,10 @@ import (
 type GroupBy interface {
 	sqlcore.SqlReady
 	sqlcore.SqlPart
+	Having(cond sqlexp.Expr) Having
 	OrderBy(first sqlexp.Expr, rest ...sqlexp.Expr) OrderBy
+	Limit(count int) Limit
+	Offset(skip int) Limit
 }
 
 type groupBy struct {
@@ -19,6 +22,11 @@ type groupBy struct {
 	Fields []sqlexp.Expr
 }
 
+func (this *groupBy) Having(cond sqlexp.Expr) Having {
+	sh := &having{GroupBy: this, Cond: cond}
+	return sh
+}
+
 func (this *groupBy) OrderBy(first sqlexp.Expr, rest ...sqlexp.Expr) OrderBy {
 	fields := []sqlexp.Expr{first}
 	fields = append(fields, rest...)
@@ -26,6 +34,16 @@ func (this *groupBy) OrderBy(first sqlexp.Expr, rest ...sqlexp.Expr) OrderBy {
 	return so
 }
 
+func (this *groupBy) Limit(count int) Limit {
+	sl := &limit{GroupBy: this, Count: &count}
+	return sl
+}
+
+func (this *groupBy) Offset(skip int) Limit {
+	sl := &limit{GroupBy: this, Skip: &skip}
+	return sl
+}
+
 func (this *groupBy) IsTableBased() (bool, sqlcore.Table) {
 	return false, nil
 }
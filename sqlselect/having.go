@@ -0,0 +1,94 @@
+package sqlselect
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+type Having interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+	OrderBy(first sqlexp.Expr, rest ...sqlexp.Expr) OrderBy
+	Limit(count int) Limit
+	Offset(skip int) Limit
+}
+
+type having struct {
+	// parent
+	GroupBy *groupBy
+	// data
+	Cond sqlexp.Expr
+}
+
+func (this *having) OrderBy(first sqlexp.Expr, rest ...sqlexp.Expr) OrderBy {
+	fields := []sqlexp.Expr{first}
+	fields = append(fields, rest...)
+	so := &orderBy{Having: this, Fields: fields}
+	return so
+}
+
+func (this *having) Limit(count int) Limit {
+	sl := &limit{Having: this, Count: &count}
+	return sl
+}
+
+func (this *having) Offset(skip int) Limit {
+	sl := &limit{Having: this, Skip: &skip}
+	return sl
+}
+
+func (this *having) IsTableBased() (bool, sqlcore.Table) {
+	return false, nil
+}
+
+func (this *having) GetColumnCount() (int, error) {
+	maker := &maker{}
+	return maker.GetColumnCount(this)
+}
+
+func (this *having) ColumnIsAmbiguous(name string) (bool, error) {
+	maker := &maker{}
+	return maker.ColumnIsAmbiguous(name, this)
+}
+
+func (this *having) ColumnExists(name string) (bool, error) {
+	maker := &maker{}
+	return maker.FindColumn(name, this)
+}
+
+func (this *having) buildHavingSectionSql(maker *maker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	stat.WriteString(maker.Format.SectionDivider)
+	stat.WriteString(maker.Format.GetLeadingSpace())
+	stat.WriteString("having ")
+	context := maker.GetExprBuildContext(
+		sqlcore.SPK_SELECT_HAVING, sqlcore.SSPK_EXPR1, stack, maker.Format)
+	stat2, err := this.Cond.GetSql(context)
+	if err != nil {
+		return err
+	}
+	stat.AppendStatPart(stat2)
+	return nil
+}
+
+func (this *having) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &maker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, nil
+}
+
+func (this *having) Validate(format *sqlcore.Format) error {
+	_, err := this.GetSql(format)
+	return err
+}
+
+func (this *having) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_SELECT_HAVING
+}
+
+func (this *having) GetParent() sqlcore.SqlPart {
+	return this.GroupBy
+}
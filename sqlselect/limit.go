@@ -0,0 +1,205 @@
+package sqlselect
+
+// Paging section of select statement. Rendered depending on dialect:
+//      PostgreSQL, MySQL, SQLite:
+//          limit <count> offset <skip>
+//      Microsoft T-SQL:
+//          select top (<count>) ...
+//          or
+//          order by ... offset <skip> rows fetch next <count> rows only
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+type Limit interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+	Limit(count int) Limit
+	Offset(skip int) Limit
+}
+
+type limit struct {
+	// parent
+	From    *from
+	Where   *where
+	GroupBy *groupBy
+	Having  *having
+	OrderBy *orderBy
+	// parent compound statement
+	Compound *compound
+	// data
+	Count *int
+	Skip  *int
+}
+
+func (this *limit) Limit(count int) Limit {
+	sl := *this
+	sl.Count = &count
+	return &sl
+}
+
+func (this *limit) Offset(skip int) Limit {
+	sl := *this
+	sl.Skip = &skip
+	return &sl
+}
+
+func (this *limit) IsTableBased() (bool, sqlcore.Table) {
+	return false, nil
+}
+
+func (this *limit) GetColumnCount() (int, error) {
+	maker := &maker{}
+	return maker.GetColumnCount(this)
+}
+
+func (this *limit) ColumnIsAmbiguous(name string) (bool, error) {
+	maker := &maker{}
+	return maker.ColumnIsAmbiguous(name, this)
+}
+
+func (this *limit) ColumnExists(name string) (bool, error) {
+	maker := &maker{}
+	return maker.FindColumn(name, this)
+}
+
+func (this *limit) validate() error {
+	if this.Count != nil && *this.Count < 0 {
+		return e("Row count in \"limit\" section can't be negative: %d",
+			*this.Count)
+	}
+	if this.Skip != nil && *this.Skip < 0 {
+		return e("Row count in \"offset\" section can't be negative: %d",
+			*this.Skip)
+	}
+	return nil
+}
+
+// Microsoft T-SQL doesn't support "limit" section,
+// so when no offset specified "top" construction used instead,
+// except compound statement, which doesn't have own select section.
+func (this *limit) useTop(maker *maker) bool {
+	return maker.Format.Dialect == sqldef.DI_MSTSQL && maker.Compound == nil &&
+		this.Count != nil && this.Skip == nil
+}
+
+func (this *limit) buildTopSectionSql(maker *maker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	context := maker.GetExprBuildContext(
+		sqlcore.SPK_SELECT_LIMIT, sqlcore.SSPK_EXPR1, stack, maker.Format)
+	ef := sqlexp.Factory()
+	stat2, err := ef.Value(*this.Count).GetSql(context)
+	if err != nil {
+		return err
+	}
+	stat.AppendStatPartsFormat("top (%s) ", stat2)
+	return nil
+}
+
+func (this *limit) buildLimitSectionSql(maker *maker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	dialect := maker.Format.Dialect
+	if this.Count == nil && this.Skip == nil || this.useTop(maker) {
+		return nil
+	}
+	context := maker.GetExprBuildContext(
+		sqlcore.SPK_SELECT_LIMIT, sqlcore.SSPK_EXPR1, stack, maker.Format)
+	ef := sqlexp.Factory()
+	var count, skip *sqlcore.Statement
+	var err error
+	if dialect == sqldef.DI_MSTSQL && this.Skip == nil {
+		skip, err = ef.Value(0).GetSql(context)
+		if err != nil {
+			return err
+		}
+	}
+	if this.Count != nil {
+		count, err = ef.Value(*this.Count).GetSql(context)
+		if err != nil {
+			return err
+		}
+	}
+	if this.Skip != nil {
+		skip, err = ef.Value(*this.Skip).GetSql(context)
+		if err != nil {
+			return err
+		}
+	}
+	stat.WriteString(maker.Format.SectionDivider)
+	stat.WriteString(maker.Format.GetLeadingSpace())
+	switch dialect {
+	case sqldef.DI_MSTSQL:
+		if this.OrderBy == nil {
+			return e("%v dialect require \"order by\" section "+
+				"to use \"offset\" or compound statement "+
+				"with \"limit\"", dialect)
+		}
+		stat.AppendStatPartsFormat("offset %s rows", skip)
+		if count != nil {
+			stat.AppendStatPartsFormat(" fetch next %s rows only", count)
+		}
+	case sqldef.DI_PGSQL:
+		if count != nil {
+			stat.AppendStatPartsFormat("limit %s", count)
+			if skip != nil {
+				stat.WriteString(" ")
+			}
+		}
+		if skip != nil {
+			stat.AppendStatPartsFormat("offset %s", skip)
+		}
+	case sqldef.DI_MYSQL, sqldef.DI_SQLITE:
+		// MySQL and SQLite doesn't allow "offset" without "limit",
+		// so use maximum allowed row count in such case
+		if count != nil {
+			stat.AppendStatPartsFormat("limit %s", count)
+		} else if dialect == sqldef.DI_MYSQL {
+			stat.WriteString("limit 18446744073709551615")
+		} else {
+			stat.WriteString("limit -1")
+		}
+		if skip != nil {
+			stat.AppendStatPartsFormat(" offset %s", skip)
+		}
+	default:
+		return e("Can't produce \"limit\" section in notation \"%v\"", dialect)
+	}
+	return nil
+}
+
+func (this *limit) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &maker{}
+	err := maker.BuildSql(this, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, nil
+}
+
+func (this *limit) Validate(format *sqlcore.Format) error {
+	_, err := this.GetSql(format)
+	return err
+}
+
+func (this *limit) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_SELECT_LIMIT
+}
+
+func (this *limit) GetParent() sqlcore.SqlPart {
+	if this.OrderBy != nil {
+		return this.OrderBy
+	} else if this.Compound != nil {
+		return this.Compound
+	} else if this.Having != nil {
+		return this.Having
+	} else if this.GroupBy != nil {
+		return this.GroupBy
+	} else if this.Where != nil {
+		return this.Where
+	} else {
+		return this.From
+	}
+}
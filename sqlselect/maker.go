@@ -23,8 +23,12 @@ type maker struct {
 	DataSources []*SelectAnalyzeDataSource
 	// Index of visibility scope of sections "from" and joins.
 	TableVisScopeIndex int
-	Batch              *sqlcore.StatementBatch
-	Format             *sqlcore.Format
+	// Paging section, if specified.
+	Limit *limit
+	// Compound statement root, if specified.
+	Compound *compound
+	Batch    *sqlcore.StatementBatch
+	Format   *sqlcore.Format
 }
 
 func NewMaker() *maker {
@@ -154,6 +158,17 @@ func (this *maker) runMaker(direct bool,
 			}
 			err := this.AddDataSource(sect.DataSource, fields)
 			return err
+		case sqlcore.SPK_SELECT_LIMIT:
+			sect := part.(*limit)
+			this.Limit = sect
+			return sect.validate()
+		case sqlcore.SPK_SELECT_COMPOUND:
+			sect := part.(*compound)
+			this.Compound = sect
+			// let fields from "order by" section
+			// refer to compound statement itself
+			err := this.AddDataSource(sect, nil)
+			return err
 			//case SST_SELECT_WHERE:
 			//case SST_SELECT_GROUP_BY:
 			//case SST_SELECT_ORDER_BY:
@@ -173,9 +188,18 @@ func (this *maker) runMaker(direct bool,
 		case sqlcore.SPK_SELECT_GROUP_BY:
 			sect := part.(*groupBy)
 			err = sect.buildGroupBySectionSql(this, this.Batch.Last(), stack)
+		case sqlcore.SPK_SELECT_HAVING:
+			sect := part.(*having)
+			err = sect.buildHavingSectionSql(this, this.Batch.Last(), stack)
 		case sqlcore.SPK_SELECT_ORDER_BY:
 			sect := part.(*orderBy)
 			err = sect.buildOrderBySectionSql(this, this.Batch.Last(), stack)
+		case sqlcore.SPK_SELECT_LIMIT:
+			sect := part.(*limit)
+			err = sect.buildLimitSectionSql(this, this.Batch.Last(), stack)
+		case sqlcore.SPK_SELECT_COMPOUND:
+			sect := part.(*compound)
+			err = sect.buildCompoundSectionSql(this, this.Batch.Last(), stack)
 		default:
 			err = e("Unexpected section during generating "+
 				"\"select\" statement: %v", part)
@@ -191,6 +215,9 @@ func (this *maker) ColumnIsAmbiguous(name string, part sqlcore.SqlPart) (bool, e
 		return false, err
 	}
 	r := sqlcore.GetSqlPartRoot(part)
+	if root, ok := r.(*compound); ok {
+		return root.ColumnIsAmbiguous(name)
+	}
 	root := r.(*sel)
 	var found, ambiguous bool
 	if len(root.SelExprs) > 0 {
@@ -230,6 +257,9 @@ func (this *maker) FindColumn(name string, part sqlcore.SqlPart) (bool, error) {
 		return false, err
 	}
 	r := sqlcore.GetSqlPartRoot(part)
+	if root, ok := r.(*compound); ok {
+		return root.ColumnExists(name)
+	}
 	root := r.(*sel)
 	if len(root.SelExprs) > 0 {
 		for _, expr := range root.SelExprs {
@@ -258,6 +288,9 @@ func (this *maker) GetColumnCount(part sqlcore.SqlPart) (int, error) {
 		return 0, err
 	}
 	r := sqlcore.GetSqlPartRoot(part)
+	if root, ok := r.(*compound); ok {
+		return root.GetColumnCount()
+	}
 	root := r.(*sel)
 	if len(root.SelExprs) == 0 {
 		count := 0
@@ -8,6 +8,8 @@ import (
 type OrderBy interface {
 	sqlcore.SqlReady
 	sqlcore.SqlPart
+	Limit(count int) Limit
+	Offset(skip int) Limit
 }
 
 type orderBy struct {
@@ -15,10 +17,23 @@ type orderBy struct {
 	From    *from
 	Where   *where
 	GroupBy *groupBy
+	Having  *having
+	// parent compound statement
+	Compound *compound
 	// data
 	Fields []sqlexp.Expr
 }
 
+func (this *orderBy) Limit(count int) Limit {
+	sl := &limit{OrderBy: this, Count: &count}
+	return sl
+}
+
+func (this *orderBy) Offset(skip int) Limit {
+	sl := &limit{OrderBy: this, Skip: &skip}
+	return sl
+}
+
 func (this *orderBy) IsTableBased() (bool, sqlcore.Table) {
 	return false, nil
 }
@@ -77,7 +92,11 @@ func (this *orderBy) GetPartKind() sqlcore.SqlPartKind {
 }
 
 func (this *orderBy) GetParent() sqlcore.SqlPart {
-	if this.GroupBy != nil {
+	if this.Compound != nil {
+		return this.Compound
+	} else if this.Having != nil {
+		return this.Having
+	} else if this.GroupBy != nil {
 		return this.GroupBy
 	} else if this.Where != nil {
 		return this.Where
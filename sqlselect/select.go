@@ -11,6 +11,10 @@ type Select interface {
 }
 
 type sel struct {
+	// common table expressions: with <name> as (...)
+	With *with
+	// eliminate duplicate rows: select distinct ...
+	Distinct bool
 	// expressions from section: select <expr1, expr2, ...> from
 	SelExprs []sqlexp.Expr
 }
@@ -20,6 +24,11 @@ func NewSelect(selExprs ...sqlexp.Expr) Select {
 	return s
 }
 
+func NewSelectDistinct(selExprs ...sqlexp.Expr) Select {
+	s := &sel{Distinct: true, SelExprs: selExprs}
+	return s
+}
+
 func (this *sel) From(query sqlcore.Query) From {
 	sf := &from{Root: this, DataSource: query}
 	return sf
@@ -35,11 +44,15 @@ func (this *sel) buildFieldsSectionSql(maker *maker,
 			query := entry.DataSource
 			queryAlias, aliasBased := query.(sqlcore.QueryAlias)
 			tableBased, table := query.IsTableBased()
+			queryNamed, namedBased := query.(sqlcore.QueryNamed)
 			if aliasBased {
 				stat.WriteString("%s.*", queryAlias.GetAlias())
 			} else if tableBased {
 				stat.WriteString("%s.*", maker.Format.FormatTableName(
 					table.GetName() /*, table.Db.Name*/))
+			} else if namedBased {
+				stat.WriteString("%s.*", maker.Format.FormatObjectName(
+					queryNamed.GetName()))
 			} else {
 				return e("Can't point to the object, since no name, neither alias specified")
 			}
@@ -67,7 +80,23 @@ func (this *sel) buildFieldsSectionSql(maker *maker,
 func (this *sel) buildSelectSectionSql(maker *maker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
 	stat.WriteString(maker.Format.GetLeadingSpace())
+	if this.With != nil {
+		err := this.With.buildWithSectionSql(maker, stat, stack)
+		if err != nil {
+			return err
+		}
+	}
 	stat.WriteString("select ")
+	if this.Distinct {
+		stat.WriteString("distinct ")
+	}
+	// T-SQL paging without offset is specified in select section
+	if maker.Limit != nil && maker.Limit.useTop(maker) {
+		err := maker.Limit.buildTopSectionSql(maker, stat, stack)
+		if err != nil {
+			return err
+		}
+	}
 	// generate fields section sql
 	err := this.buildFieldsSectionSql(maker, stat, stack)
 	if err != nil {
@@ -0,0 +1,48 @@
+package sqlselect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldb"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+func newEmpTable() *sqldb.TableDef {
+	t := sqldb.Table("emp")
+	t.Fields.AddAutoinc("id")
+	t.Fields.AddInt("mgr")
+	t.Fields.AddUnicodeVariable("name", 50)
+	return t
+}
+
+func TestCorrelatedSubqueryOnSameTable(t *testing.T) {
+	ef := sqlexp.Factory()
+	emp := newEmpTable()
+	e2 := ef.TableAlias(emp, "e2")
+	cases := []struct {
+		name  string
+		inner sqlexp.Expr
+		want  string
+	}{
+		{"outer table", ef.Field(emp, "id"), `where e2."mgr" = "emp"."id"`},
+		{"inner alias", ef.Field(e2, "id"), `where e2."mgr" = e2."id"`},
+	}
+	for _, c := range cases {
+		sub := NewSelect(ef.Field(e2, "id")).From(e2).
+			Where(ef.Equal(ef.Field(e2, "mgr"), c.inner))
+		sel := NewSelect(ef.Field(emp, "name")).From(emp).
+			Where(ef.Exists(sub))
+		batch, err := sel.GetSql(sqlcore.NewFormat(sqldef.DI_PGSQL))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		sql := batch.Items[0].Sql()
+		if !strings.Contains(sql, c.want) {
+			t.Errorf("%s: expected %q in:\n%s", c.name, c.want, sql)
+		}
+	}
+}
@@ -10,6 +10,8 @@ type Where interface {
 	sqlcore.SqlPart
 	OrderBy(firstExpr sqlexp.Expr, restExprs ...sqlexp.Expr) OrderBy
 	GroupBy(firstExpr sqlexp.Expr, restExprs ...sqlexp.Expr) GroupBy
+	Limit(count int) Limit
+	Offset(skip int) Limit
 }
 
 type where struct {
@@ -33,6 +35,16 @@ func (this *where) GroupBy(firstExpr sqlexp.Expr, restExprs ...sqlexp.Expr) Grou
 	return sg
 }
 
+func (this *where) Limit(count int) Limit {
+	sl := &limit{Where: this, Count: &count}
+	return sl
+}
+
+func (this *where) Offset(skip int) Limit {
+	sl := &limit{Where: this, Skip: &skip}
+	return sl
+}
+
 func (this *where) IsTableBased() (bool, sqlcore.Table) {
 	return false, nil
 }
@@ -0,0 +1,211 @@
+package sqlselect
+
+// Generate sql statement according to the syntax:
+//      with [recursive] name1 [(column1, column2, ...)] as (
+//          select ...
+//      ), name2 as (
+//          select ...
+//      )
+//      select ... from name1 ...
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
+	"github.com/d2r2/sqlg/sqlexp"
+)
+
+// Common table expression, which can be used as data source
+// in "from" and "join" sections, once registered with With.
+type Cte interface {
+	sqlcore.QueryNamed
+	As(sel sqlcore.SqlReady) Cte
+}
+
+type cte struct {
+	Name      string
+	Columns   []string
+	Recursive bool
+	Query     sqlcore.SqlReady
+}
+
+func NewCte(name string, columns ...string) Cte {
+	c := &cte{Name: name, Columns: columns}
+	return c
+}
+
+// Recursive common table expression should be created before
+// its statement, since statement refers to expression itself.
+func NewRecursiveCte(name string, columns ...string) Cte {
+	c := &cte{Name: name, Columns: columns, Recursive: true}
+	return c
+}
+
+func (this *cte) As(sel sqlcore.SqlReady) Cte {
+	this.Query = sel
+	return this
+}
+
+func (this *cte) GetName() string {
+	return this.Name
+}
+
+func (this *cte) getQuery() (sqlcore.Query, error) {
+	query, ok := this.Query.(sqlcore.Query)
+	if !ok {
+		return nil, e("Can't get columns of common table expression \"%s\", "+
+			"since statement is not a query", this.Name)
+	}
+	return query, nil
+}
+
+func (this *cte) IsTableBased() (bool, sqlcore.Table) {
+	return false, nil
+}
+
+func (this *cte) getColumnList() sqlcore.ColumnList {
+	return sqlcore.ColumnList{Columns: this.Columns, GetQuery: this.getQuery}
+}
+
+func (this *cte) GetColumnCount() (int, error) {
+	return this.getColumnList().GetColumnCount()
+}
+
+func (this *cte) ColumnIsAmbiguous(name string) (bool, error) {
+	return this.getColumnList().ColumnIsAmbiguous(name)
+}
+
+func (this *cte) ColumnExists(name string) (bool, error) {
+	return this.getColumnList().ColumnExists(name)
+}
+
+func (this *cte) buildCteSql(maker *maker, stat *sqlcore.Statement) error {
+	if this.Query == nil {
+		return e("Statement of common table expression \"%s\" is nil",
+			this.Name)
+	}
+	stat.WriteString(maker.Format.FormatObjectName(this.Name))
+	if len(this.Columns) > 0 {
+		stat.WriteString(" (")
+		for i, column := range this.Columns {
+			stat.WriteString(maker.Format.FormatObjectName(column))
+			if i < len(this.Columns)-1 {
+				stat.WriteString(", ")
+			}
+		}
+		stat.WriteString(")")
+	}
+	if maker.Format.ColumnNameAndCountValidationIsOn() && len(this.Columns) > 0 {
+		query, err := this.getQuery()
+		if err != nil {
+			return err
+		}
+		c, err := query.GetColumnCount()
+		if err != nil {
+			return err
+		}
+		if c != len(this.Columns) {
+			return e("Column count doesn't match in common table "+
+				"expression \"%s\": %d <> %d", this.Name, len(this.Columns), c)
+		}
+	}
+	stat.WriteString(" as (")
+	stat.WriteString(maker.Format.SectionDivider)
+	maker.Format.IncIndentLevel()
+	batch, err := this.Query.GetSql(maker.Format)
+	maker.Format.DecIndentLevel()
+	if err != nil {
+		return err
+	}
+	if len(batch.Items) > 1 {
+		return e("Can't use multiple sql statments in common "+
+			"table expression \"%s\": %v", this.Name, batch)
+	}
+	stat.AppendStatPart(batch.Items[0])
+	stat.WriteString(maker.Format.SectionDivider)
+	stat.WriteString(maker.Format.GetLeadingSpace())
+	stat.WriteString(")")
+	return nil
+}
+
+type With interface {
+	With(name string, sel sqlcore.SqlReady) With
+	WithCte(cte Cte) With
+	Cte(name string) Cte
+	Select(selExprs ...sqlexp.Expr) Select
+	SelectDistinct(selExprs ...sqlexp.Expr) Select
+}
+
+type with struct {
+	Items []*cte
+}
+
+func NewWith(name string, sel sqlcore.SqlReady) With {
+	return NewWithCte(NewCte(name).As(sel))
+}
+
+func NewWithCte(cte Cte) With {
+	w := &with{}
+	return w.WithCte(cte)
+}
+
+func (this *with) With(name string, sel sqlcore.SqlReady) With {
+	return this.WithCte(NewCte(name).As(sel))
+}
+
+func (this *with) WithCte(c Cte) With {
+	items := make([]*cte, len(this.Items), len(this.Items)+1)
+	copy(items, this.Items)
+	w := &with{Items: append(items, c.(*cte))}
+	return w
+}
+
+// Find registered common table expression to use it as data source.
+func (this *with) Cte(name string) Cte {
+	for _, item := range this.Items {
+		if item.Name == name {
+			return item
+		}
+	}
+	return nil
+}
+
+func (this *with) Select(selExprs ...sqlexp.Expr) Select {
+	s := &sel{With: this, SelExprs: selExprs}
+	return s
+}
+
+func (this *with) SelectDistinct(selExprs ...sqlexp.Expr) Select {
+	s := &sel{With: this, Distinct: true, SelExprs: selExprs}
+	return s
+}
+
+func (this *with) buildWithSectionSql(maker *maker,
+	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
+	recursive := false
+	for i, item := range this.Items {
+		for _, item2 := range this.Items[:i] {
+			if item.Name == item2.Name {
+				return e("Common table expression \"%s\" "+
+					"specified more than once", item.Name)
+			}
+		}
+		recursive = recursive || item.Recursive
+	}
+	stat.WriteString("with ")
+	// Microsoft T-SQL doesn't use "recursive" keyword
+	if recursive && maker.Format.Dialect != sqldef.DI_MSTSQL {
+		stat.WriteString("recursive ")
+	}
+	for i, item := range this.Items {
+		if i > 0 {
+			stat.WriteString(", ")
+		}
+		err := item.buildCteSql(maker, stat)
+		if err != nil {
+			return err
+		}
+	}
+	stat.WriteString(maker.Format.SectionDivider)
+	stat.WriteString(maker.Format.GetLeadingSpace())
+	return nil
+}
@@ -0,0 +1,181 @@
+package sqltx
+
+import (
+	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
+)
+
+type savepointMaker struct {
+	Format *sqlcore.Format
+	Batch  *sqlcore.StatementBatch
+}
+
+func (this *savepointMaker) runMaker(direct bool,
+	part sqlcore.SqlPart, stack *sqlcore.CallStack) error {
+	if direct == false {
+		stat := this.Batch.Last()
+		switch part.GetPartKind() {
+		case sqlcore.SPK_SAVEPOINT:
+			sect := part.(*savepoint)
+			return sect.buildSavepointSql(this.Format, stat)
+		case sqlcore.SPK_RELEASE_SAVEPOINT:
+			sect := part.(*releaseSavepoint)
+			// T-SQL doesn't release savepoints explicitly,
+			// they're kept until transaction end
+			if this.Format.Dialect == sqldef.DI_MSTSQL {
+				this.Batch.Remove(stat)
+				return nil
+			}
+			return sect.buildReleaseSavepointSql(this.Format, stat)
+		case sqlcore.SPK_ROLLBACK_SAVEPOINT:
+			sect := part.(*rollbackSavepoint)
+			return sect.buildRollbackSavepointSql(this.Format, stat)
+		default:
+			return e("Unexpected section during generating "+
+				"savepoint statement: %v", part)
+		}
+	}
+	return nil
+}
+
+func (this *savepointMaker) BuildSql(part sqlcore.SqlPart,
+	format *sqlcore.Format) error {
+	f := *format
+	this.Format = &f
+	this.Batch = sqlcore.NewStatementBatch()
+	this.Batch.Add(sqlcore.NewStatement(sqlcore.SS_EXEC))
+	return sqlcore.IterateSqlParents(false, part, this.runMaker)
+}
+
+func buildSavepointSql(part sqlcore.SqlPart,
+	format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	maker := &savepointMaker{}
+	err := maker.BuildSql(part, format)
+	if err != nil {
+		return nil, err
+	}
+	return maker.Batch, err
+}
+
+type Savepoint interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+}
+
+type savepoint struct {
+	Name string
+}
+
+func NewSavepoint(name string) Savepoint {
+	r := &savepoint{Name: name}
+	return r
+}
+
+// Build statement to set savepoint in current transaction:
+//
+//	savepoint sp1
+//	save transaction sp1 (T-SQL)
+func (this *savepoint) buildSavepointSql(format *sqlcore.Format,
+	stat *sqlcore.Statement) error {
+	stat.WriteString(format.GetLeadingSpace())
+	if format.Dialect == sqldef.DI_MSTSQL {
+		stat.WriteString("save transaction ")
+	} else {
+		stat.WriteString("savepoint ")
+	}
+	stat.WriteString(format.FormatObjectName(this.Name))
+	return nil
+}
+
+func (this *savepoint) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	return buildSavepointSql(this, format)
+}
+
+func (this *savepoint) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_SAVEPOINT
+}
+
+func (this *savepoint) GetParent() sqlcore.SqlPart {
+	return nil
+}
+
+type ReleaseSavepoint interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+}
+
+type releaseSavepoint struct {
+	Name string
+}
+
+// NewReleaseSavepoint create statement to release savepoint;
+// in T-SQL it's rendered as empty batch.
+func NewReleaseSavepoint(name string) ReleaseSavepoint {
+	r := &releaseSavepoint{Name: name}
+	return r
+}
+
+// Build statement to release savepoint:
+//
+//	release savepoint sp1
+func (this *releaseSavepoint) buildReleaseSavepointSql(format *sqlcore.Format,
+	stat *sqlcore.Statement) error {
+	stat.WriteString(format.GetLeadingSpace())
+	stat.WriteString("release savepoint ")
+	stat.WriteString(format.FormatObjectName(this.Name))
+	return nil
+}
+
+func (this *releaseSavepoint) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	return buildSavepointSql(this, format)
+}
+
+func (this *releaseSavepoint) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_RELEASE_SAVEPOINT
+}
+
+func (this *releaseSavepoint) GetParent() sqlcore.SqlPart {
+	return nil
+}
+
+type RollbackSavepoint interface {
+	sqlcore.SqlReady
+	sqlcore.SqlPart
+}
+
+type rollbackSavepoint struct {
+	Name string
+}
+
+func NewRollbackSavepoint(name string) RollbackSavepoint {
+	r := &rollbackSavepoint{Name: name}
+	return r
+}
+
+// Build statement to roll back transaction to savepoint:
+//
+//	rollback to savepoint sp1
+//	rollback transaction sp1 (T-SQL)
+func (this *rollbackSavepoint) buildRollbackSavepointSql(format *sqlcore.Format,
+	stat *sqlcore.Statement) error {
+	stat.WriteString(format.GetLeadingSpace())
+	if format.Dialect == sqldef.DI_MSTSQL {
+		stat.WriteString("rollback transaction ")
+	} else {
+		stat.WriteString("rollback to savepoint ")
+	}
+	stat.WriteString(format.FormatObjectName(this.Name))
+	return nil
+}
+
+func (this *rollbackSavepoint) GetSql(format *sqlcore.Format) (*sqlcore.StatementBatch, error) {
+	return buildSavepointSql(this, format)
+}
+
+func (this *rollbackSavepoint) GetPartKind() sqlcore.SqlPartKind {
+	return sqlcore.SPK_ROLLBACK_SAVEPOINT
+}
+
+func (this *rollbackSavepoint) GetParent() sqlcore.SqlPart {
+	return nil
+}
@@ -0,0 +1,124 @@
+package sqltx
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/d2r2/sqlg/sqlcore"
+)
+
+// Tx is a unit of work, either top level transaction
+// or savepoint inside of it. Tx implement sqlcore.Querier,
+// so batches could be executed within.
+type Tx struct {
+	Tx     *sql.Tx
+	Format *sqlcore.Format
+	// nesting level, which is 0 for top level transaction
+	Level int
+}
+
+func (this *Tx) ExecContext(ctx context.Context, query string,
+	args ...interface{}) (sql.Result, error) {
+	return this.Tx.ExecContext(ctx, query, args...)
+}
+
+func (this *Tx) QueryContext(ctx context.Context, query string,
+	args ...interface{}) (*sql.Rows, error) {
+	return this.Tx.QueryContext(ctx, query, args...)
+}
+
+func (this *Tx) QueryRowContext(ctx context.Context, query string,
+	args ...interface{}) *sql.Row {
+	return this.Tx.QueryRowContext(ctx, query, args...)
+}
+
+func (this *Tx) getSavepointName() string {
+	return f("sqlg_sp%d", this.Level)
+}
+
+func (this *Tx) exec(ctx context.Context, s sqlcore.SqlReady) error {
+	batch, err := s.GetSql(this.Format)
+	if err != nil {
+		return err
+	}
+	_, err = batch.ExecContext(ctx, this.Tx)
+	return err
+}
+
+type TxFunc func(tx *Tx) error
+
+// Run execute fn as a unit of work, which is committed when fn
+// return nil, and rolled back on error or panic. When db is
+// *sql.DB or *sql.Conn, new transaction is started. When db is
+// *Tx (or *sql.Tx), it's already inside of transaction,
+// so unit of work is nested with savepoint.
+func Run(ctx context.Context, db sqlcore.Querier, format *sqlcore.Format,
+	fn TxFunc) error {
+	switch v := db.(type) {
+	case *Tx:
+		return runNested(ctx, v, fn)
+	case *sql.Tx:
+		return runNested(ctx, &Tx{Tx: v, Format: format}, fn)
+	case sqlcore.TxBeginner:
+		return RunTx(ctx, v, format, nil, fn)
+	default:
+		return e("Can't start transaction on %T", db)
+	}
+}
+
+// RunTx start new transaction with options opts and execute
+// fn inside; commit on success and roll back on error or panic.
+func RunTx(ctx context.Context, db sqlcore.TxBeginner, format *sqlcore.Format,
+	opts *sql.TxOptions, fn TxFunc) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if err2 := tx.Rollback(); err2 != nil {
+				log.Error(err2)
+			}
+			panic(r)
+		}
+	}()
+	err = fn(&Tx{Tx: tx, Format: format})
+	if err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			log.Error(err2)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func runNested(ctx context.Context, parent *Tx, fn TxFunc) (err error) {
+	tx := &Tx{Tx: parent.Tx, Format: parent.Format, Level: parent.Level + 1}
+	name := tx.getSavepointName()
+	err = tx.exec(ctx, NewSavepoint(name))
+	if err != nil {
+		return err
+	}
+	rollback := func() {
+		if err2 := tx.exec(ctx, NewRollbackSavepoint(name)); err2 != nil {
+			log.Error(err2)
+			return
+		}
+		// savepoint stay alive after rollback to it, so release it
+		if err2 := tx.exec(ctx, NewReleaseSavepoint(name)); err2 != nil {
+			log.Error(err2)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			rollback()
+			panic(r)
+		}
+	}()
+	err = fn(tx)
+	if err != nil {
+		rollback()
+		return err
+	}
+	return tx.exec(ctx, NewReleaseSavepoint(name))
+}
@@ -2,6 +2,7 @@ package sqlupdate
 
 import (
 	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
 	"github.com/d2r2/sqlg/sqlexp"
 )
 
@@ -10,7 +11,10 @@ type From interface {
 	InnerJoin(query sqlcore.Query, joinCond sqlexp.Expr) From
 	LeftJoin(query sqlcore.Query, joinCond sqlexp.Expr) From
 	RightJoin(query sqlcore.Query, joinCond sqlexp.Expr) From
+	FullJoin(query sqlcore.Query, joinCond sqlexp.Expr) From
+	CrossJoin(query sqlcore.Query) From
 	Where(cond sqlexp.Expr) Where
+	Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning
 }
 
 type from struct {
@@ -41,6 +45,23 @@ func (this *from) RightJoin(query sqlcore.Query, joinCond sqlexp.Expr) From {
 	return sf
 }
 
+func (this *from) FullJoin(query sqlcore.Query, joinCond sqlexp.Expr) From {
+	sf := &from{From: this, DataSource: query,
+		JoinKind: sqlcore.JK_FULL, JoinCond: joinCond}
+	return sf
+}
+
+// Cross join doesn't have join condition.
+func (this *from) CrossJoin(query sqlcore.Query) From {
+	sf := &from{From: this, DataSource: query,
+		JoinKind: sqlcore.JK_CROSS}
+	return sf
+}
+
+func (this *from) Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning {
+	return newReturning(this, first, rest...)
+}
+
 func (this *from) Where(cond sqlexp.Expr) Where {
 	sw := &where{From: this, Cond: cond}
 	return sw
@@ -48,7 +69,7 @@ func (this *from) Where(cond sqlexp.Expr) Where {
 
 func (this *from) buildFromSectionSql(maker *updateMaker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
-	if this.JoinCond == nil {
+	if this.From == nil {
 		stat2, err := maker.Format.FormatDataSourceRef(this.DataSource)
 		if err != nil {
 			return err
@@ -63,7 +84,20 @@ func (this *from) buildFromSectionSql(maker *updateMaker,
 		jk := map[sqlcore.JoinKind]string{
 			sqlcore.JK_INNER: "inner",
 			sqlcore.JK_LEFT:  "left",
-			sqlcore.JK_RIGHT: "right"}
+			sqlcore.JK_RIGHT: "right",
+			sqlcore.JK_FULL:  "full",
+			sqlcore.JK_CROSS: "cross"}
+		// MySQL doesn't support full outer join at all,
+		// SQLite supports it starting from version 3.39
+		if this.JoinKind == sqlcore.JK_FULL &&
+			maker.Format.Dialect == sqldef.DI_MYSQL {
+			return e("%v dialect doesn't support \"full join\", "+
+				"use union of \"left join\" and \"right join\" instead",
+				maker.Format.Dialect)
+		}
+		if this.JoinKind != sqlcore.JK_CROSS && this.JoinCond == nil {
+			return e("Condition of \"%s join\" is nil", jk[this.JoinKind])
+		}
 		maker.IncScopeVisIndex()
 		context := maker.GetExprBuildContext(
 			sqlcore.SPK_UPDATE_FROM_OR_JOIN, sqlcore.SSPK_EXPR1, stack, maker.Format)
@@ -74,12 +108,16 @@ func (this *from) buildFromSectionSql(maker *updateMaker,
 		stat.WriteString(maker.Format.SectionDivider)
 		stat.WriteString(maker.Format.GetLeadingSpace())
 		stat.WriteString(f("%s join ", jk[this.JoinKind]))
-		stat.AppendStatPartsFormat("%s on ", stat2)
-		stat3, err := this.JoinCond.GetSql(context)
-		if err != nil {
-			return err
+		if this.JoinKind == sqlcore.JK_CROSS {
+			stat.AppendStatPart(stat2)
+		} else {
+			stat.AppendStatPartsFormat("%s on ", stat2)
+			stat3, err := this.JoinCond.GetSql(context)
+			if err != nil {
+				return err
+			}
+			stat.AppendStatPart(stat3)
 		}
-		stat.AppendStatPart(stat3)
 	}
 	return nil
 }
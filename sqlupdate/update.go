@@ -4,8 +4,10 @@ package sqlupdate
 //      update table1
 //      set column1 = expr1, column2 = expr2, ...
 //      [from table2]
-//      [(inner|left|right) join table3 on conditions]
+//      [(inner|left|right|full) join table3 on conditions]
+//      [cross join table4]
 //      [where conditions]
+//      [returning expr1, expr2, ...]
 
 // keep temporary information about specific table
 // from "from" and "join" sections;
@@ -14,6 +16,7 @@ package sqlupdate
 
 import (
 	"github.com/d2r2/sqlg/sqlcore"
+	"github.com/d2r2/sqlg/sqldef"
 	"github.com/d2r2/sqlg/sqlexp"
 )
 
@@ -28,6 +31,7 @@ type updateMaker struct {
 	TableVisScopeIndex int
 	Format             *sqlcore.Format
 	Batch              *sqlcore.StatementBatch
+	Returning          *returning
 }
 
 // support scope visibility in section [from, join... join]
@@ -60,6 +64,9 @@ func (this *updateMaker) runMaker(direct bool,
 			}
 			err := this.AddDataSource(sect.DataSource, fields)
 			return err
+		case sqlcore.SPK_UPDATE_RETURNING:
+			sect := part.(*returning)
+			this.Returning = sect
 			//        case SST_UPDATE_WHERE:
 			//            sect := part.(*where)
 		}
@@ -75,6 +82,13 @@ func (this *updateMaker) runMaker(direct bool,
 		case sqlcore.SPK_UPDATE_WHERE:
 			sect := part.(*where)
 			err = sect.buildWhereSectionSql(this, this.Batch.Last(), stack)
+		case sqlcore.SPK_UPDATE_RETURNING:
+			// Microsoft T-SQL "output" section
+			// is generated right after "set" section
+			if this.Format.Dialect != sqldef.DI_MSTSQL {
+				sect := part.(*returning)
+				err = sect.buildReturningSectionSql(this, this.Batch.Last(), stack)
+			}
 		default:
 			err = e("Unexpected section during generating "+
 				"\"insert\" statement: %v", part)
@@ -101,8 +115,9 @@ func (this *updateMaker) GetExprBuildContext(partKind sqlcore.SqlPartKind,
 }
 
 func (this *updateMaker) ResetScopeVisIndex() {
-	// be aware that tables present in reverse order
-	this.TableVisScopeIndex = len(this.DataSources) - 1
+	// be aware that tables present in reverse order,
+	// and target table of update statement added last
+	this.TableVisScopeIndex = len(this.DataSources) - 2
 }
 
 func (this *updateMaker) IncScopeVisIndex() {
@@ -163,6 +178,7 @@ type Update interface {
 	sqlcore.SqlPart
 	From(query sqlcore.Query) From
 	Where(cond sqlexp.Expr) Where
+	Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning
 }
 
 type update struct {
@@ -185,6 +201,11 @@ func (this *update) Where(cond sqlexp.Expr) Where {
 	return uw
 }
 
+// Returning without "where" section affect all rows of the table.
+func (this *update) Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning {
+	return newReturning(this, first, rest...)
+}
+
 func (this *update) buildFieldsSectionSql(maker *updateMaker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
 	context := maker.GetExprBuildContext(sqlcore.SPK_UPDATE, sqlcore.SSPK_EXPR1,
@@ -226,6 +247,13 @@ func (this *update) buildUpdateSectionSql(maker *updateMaker,
 	if err != nil {
 		return err
 	}
+	// insert output section if necessary
+	if maker.Returning != nil && maker.Format.Dialect == sqldef.DI_MSTSQL {
+		err = maker.Returning.buildReturningSectionSql(maker, stat, stack)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 type Where interface {
 	sqlcore.SqlReady
 	sqlcore.SqlPart
+	Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning
 }
 
 type where struct {
@@ -18,6 +19,10 @@ type where struct {
 	Cond sqlexp.Expr
 }
 
+func (this *where) Returning(first sqlexp.Expr, rest ...sqlexp.Expr) Returning {
+	return newReturning(this, first, rest...)
+}
+
 func (this *where) buildWhereSectionSql(maker *updateMaker,
 	stat *sqlcore.Statement, stack *sqlcore.CallStack) error {
 	stat.WriteString(maker.Format.SectionDivider)